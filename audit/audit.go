@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/cdnjs/tools/algolia"
+	"github.com/cdnjs/tools/secrets"
 
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
@@ -124,9 +125,12 @@ func get(ctx context.Context, pkgName string, version string, stage string) (*gi
 	return res, nil
 }
 
-func NewVersionDetected(ctx context.Context, pkgName string, version string) error {
+func NewVersionDetected(ctx context.Context, pkgName string, version string, integrity string) error {
 	content := bytes.NewBufferString("")
 	fmt.Fprintf(content, "New version: %s\n", version)
+	if integrity != "" {
+		fmt.Fprintf(content, "Verified integrity: %s\n", integrity)
+	}
 
 	if err := create(ctx, pkgName, version, "new-version", content); err != nil {
 		return errors.Wrap(err, "could not create audit log file")
@@ -134,6 +138,17 @@ func NewVersionDetected(ctx context.Context, pkgName string, version string) err
 	return nil
 }
 
+func UnverifiedVersion(ctx context.Context, pkgName string, version string, reason string) error {
+	content := bytes.NewBufferString("")
+	fmt.Fprintf(content, "Version not published: %s\n", version)
+	fmt.Fprintf(content, "Could not verify tarball: %s\n", reason)
+
+	if err := create(ctx, pkgName, version, "unverified", content); err != nil {
+		return errors.Wrap(err, "could not create audit log file")
+	}
+	return nil
+}
+
 const MAX_LOGS_LENGTH = 1 * 1024 * 1024 // 1 Mb
 
 func ProcessedVersion(ctx context.Context, pkgName string, version string, logs string) error {
@@ -199,3 +214,24 @@ func WroteR2(ctx context.Context, pkgName string, version string, keys []string)
 	}
 	return nil
 }
+
+func FoundSensitiveFiles(ctx context.Context, pkgName string, version string, findings []secrets.Finding) error {
+	content := bytes.NewBufferString("")
+	fmt.Fprint(content, "Sensitive files not published:\n")
+	for _, finding := range findings {
+		if finding.Blocked {
+			fmt.Fprintf(content, "- %s: %s\n", finding.File, finding.Reason)
+		}
+	}
+	fmt.Fprint(content, "\nPublished files which may contain a secret:\n")
+	for _, finding := range findings {
+		if !finding.Blocked {
+			fmt.Fprintf(content, "- %s: %s\n", finding.File, finding.Reason)
+		}
+	}
+
+	if err := create(ctx, pkgName, version, "sensitive-files", content); err != nil {
+		return errors.Wrap(err, "could not create audit log file")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+// Severities of the diagnostics.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// Check is a kind of diagnostic, identified by a code
+// which doesn't change across releases.
+type Check struct {
+	Code        string
+	Severity    string
+	Description string
+}
+
+// The checks of the checker. Warnings can be suppressed
+// for a package, but errors can't.
+var (
+	readFailed          = Check{"read-failed", severityError, "The package file can't be read."}
+	invalidJSON         = Check{"invalid-json", severityError, "The package file isn't valid JSON."}
+	invalidSchema       = Check{"invalid-schema", severityError, "The package doesn't match the schema."}
+	invalidPath         = Check{"invalid-path", severityError, "The package file path isn't `packages/<letter>/<name>.json`."}
+	wrongDirectory      = Check{"wrong-directory", severityError, "The package file isn't in the directory of the first letter of its name."}
+	notFormatted        = Check{"not-formatted", severityError, "The package file isn't in its canonical form."}
+	invalidMigration    = Check{"invalid-migration", severityError, "The package is invalid once migrated."}
+	invalidVersionRange = Check{"invalid-version-range", severityError, "The version range isn't a valid semver range."}
+	invalidSuppression  = Check{"invalid-suppression", severityError, "A suppression doesn't name a warning."}
+	npmNotFound         = Check{"npm-not-found", severityError, "The npm package doesn't exist."}
+	noVersion           = Check{"no-version", severityError, "No version was found at the autoupdate source."}
+	noFiles             = Check{"no-files", severityError, "No files are published for a version."}
+	fileCollision       = Check{"file-collision", severityError, "Files are published at the same destination."}
+	filenameNotFound    = Check{"filename-not-found", severityError, "The filename isn't published for the most recent version."}
+	duplicateName       = Check{"duplicate-name", severityError, "Packages have the same name."}
+	nameCollision       = Check{"name-collision", severityError, "Package names only differ in case."}
+	duplicateNpmTarget  = Check{"duplicate-npm-target", severityWarning, "Packages are updated from the same npm package."}
+	duplicateGitRepo    = Check{"duplicate-git-repo", severityWarning, "Packages are updated from the same git repository."}
+	rateLimit           = Check{"rate-limit", severityWarning, "A check was skipped since the rate limit of an API was hit."}
+	missingFilename     = Check{"missing-filename", severityWarning, "The package has no filename."}
+	lowGitHubStars      = Check{"low-github-stars", severityWarning, "The GitHub repository isn't popular enough."}
+	lowNpmDownloads     = Check{"low-npm-downloads", severityWarning, "The npm package isn't downloaded enough."}
+	deadPattern         = Check{"dead-pattern", severityWarning, "A fileMap pattern matches no file in the last versions."}
+	broadPattern        = Check{"broad-pattern", severityWarning, "A fileMap pattern matches too many files."}
+	suspiciousPattern   = Check{"suspicious-pattern", severityWarning, "A fileMap pattern matches files usually not published."}
+	sensitiveFile       = Check{"sensitive-file", severityWarning, "A file matched by the fileMap looks sensitive and isn't published."}
+	possibleSecret      = Check{"possible-secret", severityWarning, "A published file may contain a secret."}
+	invalidLicense      = Check{"invalid-license", severityWarning, "The license isn't a valid SPDX expression."}
+	nonOSILicense       = Check{"non-osi-license", severityWarning, "The license isn't approved by the OSI."}
+	licenseMismatch     = Check{"license-mismatch", severityWarning, "The license differs from the one declared upstream."}
+	lintFailed          = Check{"lint-failed", severityError, "The package couldn't be linted, ex. when a lookup failed."}
+)
+
+// Checks lists all the checks, ordered by code.
+var Checks = []Check{
+	broadPattern,
+	deadPattern,
+	duplicateGitRepo,
+	duplicateName,
+	duplicateNpmTarget,
+	fileCollision,
+	filenameNotFound,
+	invalidJSON,
+	invalidLicense,
+	invalidMigration,
+	invalidPath,
+	invalidSchema,
+	invalidSuppression,
+	invalidVersionRange,
+	licenseMismatch,
+	lintFailed,
+	lowGitHubStars,
+	lowNpmDownloads,
+	missingFilename,
+	nameCollision,
+	noFiles,
+	noVersion,
+	nonOSILicense,
+	notFormatted,
+	npmNotFound,
+	possibleSecret,
+	rateLimit,
+	readFailed,
+	sensitiveFile,
+	suspiciousPattern,
+	wrongDirectory,
+}
+
+// Returns the check of a code.
+func checkOf(code string) (Check, bool) {
+	for _, c := range Checks {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return Check{}, false
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"sort"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/util"
+	"github.com/cdnjs/tools/version"
+
+	"github.com/pkg/errors"
+)
+
+// DiffResult compares the files published for the last versions
+// of a package with its old and new configurations.
+type DiffResult struct {
+	Package     string        `json:"package"`
+	OldFilename *string       `json:"oldFilename,omitempty"`
+	NewFilename *string       `json:"newFilename,omitempty"`
+	Versions    []VersionDiff `json:"versions"`
+}
+
+// VersionDiff are the files added and removed for a version by the new
+// configuration, and whether the filename of each configuration is published.
+type VersionDiff struct {
+	Version          string   `json:"version"`
+	Added            []string `json:"added"`
+	Removed          []string `json:"removed"`
+	OldFilenameFound bool     `json:"oldFilenameFound"`
+	NewFilenameFound bool     `json:"newFilenameFound"`
+}
+
+// Prints the files added and removed for the last versions of a package when
+// its configuration changes from the package file at oldPath to the one at newPath.
+// The versions are listed with the new configuration, and the old one is applied
+// to the same tarballs.
+func diffPackage(oldPath, newPath string, noPathValidation bool, n int) error {
+	// create context with file path prefix, checker logger
+	ctx := util.ContextWithEntries(util.GetCheckerEntries(newPath, logger)...)
+
+	// the old package is only read, since it is usually
+	// a previous version outside of the repository
+	src, err := ioutil.ReadFile(oldPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read old package file")
+	}
+	oldPckg, err := packages.ReadHumanJSONBytes(ctx, oldPath, src, true)
+	if err != nil {
+		return errors.Wrap(err, "invalid old package")
+	}
+
+	newPckg, err := parseHumanPackage(ctx, "", newPath, noPathValidation)
+	if err != nil {
+		return errors.Wrap(err, "could not parse new package")
+	}
+	if newPckg == nil {
+		return nil
+	}
+
+	initSandbox(ctx)
+
+	versions, err := listVersions(ctx, newPckg)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		show(ctx, noVersion, "no version found on "+*newPckg.Autoupdate.Source)
+		return nil
+	}
+	if len(versions) > n {
+		versions = versions[:n]
+	}
+
+	output.Diff = &DiffResult{
+		Package:     *newPckg.Name,
+		OldFilename: oldPckg.Filename,
+		NewFilename: newPckg.Filename,
+		Versions:    []VersionDiff{},
+	}
+
+	if !equalStrings(oldPckg.Filename, newPckg.Filename) {
+		printf("\nfilename: %s -> %s\n", describeFilename(oldPckg.Filename), describeFilename(newPckg.Filename))
+	}
+
+	printf("\n%d last version(s):\n", len(versions))
+	for _, v := range versions {
+		d, err := diffVersion(ctx, oldPckg, newPckg, v)
+		if err != nil {
+			return errors.Wrapf(err, "could not diff version %s", v.Version)
+		}
+		output.Diff.Versions = append(output.Diff.Versions, d)
+		printVersionDiff(newPckg, d)
+	}
+	return nil
+}
+
+// Processes a version with both configurations.
+func diffVersion(ctx context.Context, oldPckg, newPckg *packages.Package, v version.Version) (VersionDiff, error) {
+	buff, err := version.DownloadTar(ctx, v)
+	if err != nil {
+		return VersionDiff{}, errors.Wrap(err, "failed to download version")
+	}
+
+	oldFiles, err := publishedFiles(ctx, oldPckg, v, buff.Bytes())
+	if err != nil {
+		return VersionDiff{}, err
+	}
+	newFiles, err := publishedFiles(ctx, newPckg, v, buff.Bytes())
+	if err != nil {
+		return VersionDiff{}, err
+	}
+
+	added, removed := diffFiles(oldFiles, newFiles)
+	return VersionDiff{
+		Version:          v.Version,
+		Added:            added,
+		Removed:          removed,
+		OldFilenameFound: containsFilename(oldFiles, oldPckg.Filename),
+		NewFilenameFound: containsFilename(newFiles, newPckg.Filename),
+	}, nil
+}
+
+// Lists the files of a version published with a configuration.
+func publishedFiles(ctx context.Context, pckg *packages.Package, v version.Version, tarball []byte) ([]string, error) {
+	res, err := processTarball(ctx, pckg, v, tarball)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to process version")
+	}
+	return fileNames(res.files), nil
+}
+
+// Prints the files added and removed for a version, and
+// whether the filename is published when it changes.
+func printVersionDiff(newPckg *packages.Package, d VersionDiff) {
+	if len(d.Added) == 0 && len(d.Removed) == 0 {
+		printf("- %s: no change\n", d.Version)
+	} else {
+		printf("- %s: %d file(s) added, %d file(s) removed\n", d.Version, len(d.Added), len(d.Removed))
+		for _, file := range d.Added {
+			printf("  + %s\n", file)
+		}
+		for _, file := range d.Removed {
+			printf("  - %s\n", file)
+		}
+	}
+
+	switch {
+	case d.OldFilenameFound && !d.NewFilenameFound && newPckg.Filename != nil:
+		printf("  filename `%s` is no longer published :heavy_exclamation_mark:\n", *newPckg.Filename)
+	case !d.OldFilenameFound && d.NewFilenameFound:
+		printf("  filename `%s` is now published :heavy_check_mark:\n", *newPckg.Filename)
+	}
+}
+
+// Returns the files of new which aren't in old, and
+// the files of old which aren't in new, sorted.
+func diffFiles(old, new []string) ([]string, []string) {
+	inOld := make(map[string]bool)
+	for _, f := range old {
+		inOld[f] = true
+	}
+	inNew := make(map[string]bool)
+	for _, f := range new {
+		inNew[f] = true
+	}
+
+	added, removed := make([]string, 0), make([]string, 0)
+	for _, f := range new {
+		if !inOld[f] {
+			added = append(added, f)
+		}
+	}
+	for _, f := range old {
+		if !inNew[f] {
+			removed = append(removed, f)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func containsFilename(files []string, filename *string) bool {
+	if filename == nil {
+		return false
+	}
+	for _, f := range files {
+		if f == *filename {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func describeFilename(filename *string) string {
+	if filename == nil {
+		return "none"
+	}
+	return "`" + *filename + "`"
+}
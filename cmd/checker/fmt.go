@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+)
+
+// Rewrites a package file into its canonical form or, in check
+// mode, outputs a ci error if it isn't in its canonical form.
+func formatPackage(pckgPath string, check bool) error {
+	// create context with file path prefix, checker logger
+	ctx := util.ContextWithEntries(util.GetCheckerEntries(pckgPath, logger)...)
+
+	src, err := ioutil.ReadFile(pckgPath)
+	if err != nil {
+		show(ctx, readFailed, "failed to read")
+		return errors.Wrap(err, "failed to read package file")
+	}
+
+	formatted, err := packages.FormatHumanJSONBytes(ctx, pckgPath, src)
+	if err != nil {
+		showReadErr(ctx, src, err)
+		return nil
+	}
+
+	if bytes.Equal(src, formatted) {
+		return nil
+	}
+	if check {
+		show(ctx, notFormatted, "package is not formatted, run `checker fmt`")
+		return nil
+	}
+
+	if err := ioutil.WriteFile(pckgPath, formatted, 0644); err != nil {
+		return errors.Wrap(err, "failed to write package file")
+	}
+	log.Printf("%s formatted\n", pckgPath)
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+)
+
+// A valid package and its file.
+type lintedPackage struct {
+	path string
+	pckg *packages.Package
+}
+
+// Lints all the packages of a checkout of the packages repository,
+// checks that they are consistent with one another and prints a summary.
+func lintAll(dir string, parallel int) error {
+	files, err := listPackageFiles([]string{filepath.Join(dir, "packages")})
+	if err != nil {
+		return errors.Wrap(err, "failed to list packages")
+	}
+
+	// package paths are validated relative to the checkout
+	for i, file := range files {
+		if files[i], err = filepath.Rel(dir, file); err != nil {
+			return errors.Wrap(err, "failed to resolve package path")
+		}
+	}
+
+	linted := lintPackages(dir, files, false, parallel)
+	checkConsistency(dir, linted)
+	printSummary(len(files))
+	return nil
+}
+
+// Checks that packages don't share their name, even ignoring
+// the case, nor their autoupdate target.
+func checkConsistency(root string, linted []lintedPackage) {
+	checkUnique(root, linted, duplicateName, "name", func(p *packages.Package) (string, string, bool) {
+		return *p.Name, *p.Name, true
+	}, nil, "name `%s` is also used by %s")
+
+	checkUnique(root, linted, nameCollision, "name", func(p *packages.Package) (string, string, bool) {
+		return *p.Name, strings.ToLower(*p.Name), true
+	}, func(a, b *packages.Package) bool {
+		return *a.Name != *b.Name
+	}, "name `%s` only differs in case from the name of %s")
+
+	checkUnique(root, linted, duplicateNpmTarget, "autoupdate.target", func(p *packages.Package) (string, string, bool) {
+		return *p.Autoupdate.Target, *p.Autoupdate.Target, *p.Autoupdate.Source == "npm"
+	}, nil, "npm package `%s` is also the autoupdate target of %s")
+
+	checkUnique(root, linted, duplicateGitRepo, "autoupdate.target", func(p *packages.Package) (string, string, bool) {
+		key := strings.ToLower(packages.NormalizeRepositoryURL(*p.Autoupdate.Target))
+		return *p.Autoupdate.Target, key, *p.Autoupdate.Source == "git"
+	}, nil, "git repository `%s` is also the autoupdate target of %s")
+}
+
+// Groups packages by the key of the value of a field, if any, and outputs the
+// check at the field of each package for the packages of its group that conflict
+// with it (all of them if conflict is nil), described by a format taking the
+// value and the files of these packages.
+func checkUnique(root string, linted []lintedPackage, c Check, field string, key func(*packages.Package) (string, string, bool), conflict func(a, b *packages.Package) bool, format string) {
+	var keys []string
+	groups := make(map[string][]lintedPackage)
+	for _, l := range linted {
+		_, k, ok := key(l.pckg)
+		if !ok {
+			continue
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], l)
+	}
+
+	for _, k := range keys {
+		group := groups[k]
+		for _, l := range group {
+			var others []string
+			for _, other := range group {
+				if other.path != l.path && (conflict == nil || conflict(l.pckg, other.pckg)) {
+					others = append(others, "`"+other.path+"`")
+				}
+			}
+			if len(others) > 0 {
+				value, _, _ := key(l.pckg)
+				show(fieldContext(root, l.path, field), c, fmt.Sprintf(format, value, strings.Join(others, ", ")))
+			}
+		}
+	}
+}
+
+// Returns the context of a package file relative to root, positioned at a field.
+func fieldContext(root, pckgPath, field string) context.Context {
+	ctx := util.ContextWithEntries(util.GetCheckerEntries(pckgPath, logger)...)
+	if src, err := ioutil.ReadFile(filepath.Join(root, pckgPath)); err == nil {
+		if pos, ok := util.JSONFieldPosition(src, field); ok {
+			ctx = context.WithValue(ctx, util.Position, pos)
+		}
+	}
+	return ctx
+}
+
+// Prints the number of diagnostics, by severity and by code.
+func printSummary(pckgs int) {
+	summary := &Summary{Packages: pckgs, Codes: make(map[string]int)}
+	for _, d := range output.Diagnostics {
+		switch {
+		case d.Suppression != nil:
+			summary.Suppressed++
+		case d.Severity == severityError:
+			summary.Errors++
+		default:
+			summary.Warnings++
+		}
+		if d.Suppression == nil {
+			summary.Codes[d.Code]++
+		}
+	}
+	output.Summary = summary
+
+	printf("\n%d package(s): %d error(s), %d warning(s), %d suppressed\n", summary.Packages, summary.Errors, summary.Warnings, summary.Suppressed)
+	if len(summary.Codes) == 0 {
+		return
+	}
+
+	var codes []string
+	for code := range summary.Codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	printf("\n| code | severity | count |\n| --- | --- | --- |\n")
+	for _, code := range codes {
+		c, _ := checkOf(code)
+		printf("| %s | %s | %d |\n", code, c.Severity, summary.Codes[code])
+	}
+}
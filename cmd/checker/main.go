@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -12,15 +14,19 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/cdnjs/tools/git"
 	"github.com/cdnjs/tools/npm"
 	"github.com/cdnjs/tools/packages"
 	"github.com/cdnjs/tools/sandbox"
+	"github.com/cdnjs/tools/secrets"
+	"github.com/cdnjs/tools/spdx"
 	"github.com/cdnjs/tools/util"
 	"github.com/cdnjs/tools/version"
 
 	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 var (
@@ -32,79 +38,328 @@ var (
 
 	// regex for path in cdnjs/packages/
 	pckgPathRegex = regexp.MustCompile("^packages/([a-z0-9])/([a-zA-Z0-9._-]+).json$")
+
+	// default number of packages linted at a time
+	defaultParallel = 8
+
+	// whether versions are processed without the sandbox
+	noSandbox bool
+
+	// client of the npm registry, configured with NPM_REGISTRY_URL and NPM_TOKEN
+	npmClient = npm.DefaultClient()
 )
 
 func main() {
 	var noPathValidation bool
 	flag.BoolVar(&noPathValidation, "no-path-validation", false, "If set, all package paths are accepted.")
+	flag.BoolVar(&noSandbox, "no-sandbox", false, "If set, versions are processed without Docker, applying the fileMap without minifying files.")
+	flag.StringVar(&outputFormat, "format", formatGitHub, "Output format: github, json or sarif.")
+	exceptionsFile := flag.String("exceptions", "", "If set, the JSON file of the warnings suppressed for each package name.")
+	flag.StringVar(&util.HTTP_CACHE_DIR, "http-cache", util.HTTP_CACHE_DIR, "If set, the directory in which the responses of the npm and GitHub APIs are kept across runs.")
 	flag.Parse()
 
+	switch outputFormat {
+	case formatGitHub, formatJSON, formatSARIF:
+	default:
+		log.Fatalf("unknown format: `%s`\n", outputFormat)
+	}
+
+	if *exceptionsFile != "" {
+		if err := readExceptions(*exceptionsFile); err != nil {
+			log.Fatalf("failed to read exceptions: %s\n", err)
+		}
+	}
+
 	switch subcommand := flag.Arg(0); subcommand {
 	case "lint":
 		{
-			for _, path := range flag.Args()[1:] {
-				if err := lintPackage(path, noPathValidation); err != nil {
-					log.Fatalf("failed to lint package: %s\n", err)
-				}
-			}
+			lintFlags := flag.NewFlagSet("lint", flag.ExitOnError)
+			parallel := lintFlags.Int("parallel", defaultParallel, "Number of packages linted at a time.")
+			lintFlags.Parse(flag.Args()[1:])
+
+			lintPackages("", lintFlags.Args(), noPathValidation, *parallel)
 
-			if errCount > 0 {
-				os.Exit(1)
+			exit()
+		}
+	case "lint-all":
+		{
+			lintAllFlags := flag.NewFlagSet("lint-all", flag.ExitOnError)
+			parallel := lintAllFlags.Int("parallel", defaultParallel, "Number of packages linted at a time.")
+			lintAllFlags.Parse(flag.Args()[1:])
+
+			dir := "."
+			if lintAllFlags.NArg() > 0 {
+				dir = lintAllFlags.Arg(0)
+			}
+			if err := lintAll(dir, *parallel); err != nil {
+				log.Fatalf("failed to lint all packages: %s\n", err)
 			}
+
+			exit()
 		}
 	case "show-files":
 		{
-			if err := showFiles(flag.Arg(1), noPathValidation); err != nil {
+			showFilesFlags := flag.NewFlagSet("show-files", flag.ExitOnError)
+			var target showFilesTarget
+			showFilesFlags.StringVar(&target.version, "version", "", "If set, only the files of this version are listed.")
+			showFilesFlags.StringVar(&target.tarball, "tarball", "", "If set, the files of this local .tgz are listed instead of the versions of the package.")
+			showFilesFlags.StringVar(&target.dir, "dir", "", "If set, the files of this local unpacked directory are listed instead of the versions of the package.")
+			showFilesFlags.Parse(flag.Args()[1:])
+
+			if showFilesFlags.NArg() != 1 || !target.valid() {
+				log.Fatalf("usage: checker show-files [-version V | -tarball file.tgz | -dir directory] <package>\n")
+			}
+			if err := showFiles(showFilesFlags.Arg(0), noPathValidation, target); err != nil {
 				log.Fatalf("failed to show files: %s\n", err)
 			}
 
-			if errCount > 0 {
-				os.Exit(1)
+			exit()
+		}
+	case "diff":
+		{
+			diffFlags := flag.NewFlagSet("diff", flag.ExitOnError)
+			n := diffFlags.Int("versions", util.ImportAllMaxVersions, "Number of last versions compared.")
+			diffFlags.Parse(flag.Args()[1:])
+
+			if diffFlags.NArg() != 2 {
+				log.Fatalf("usage: checker diff [-versions N] <old package> <new package>\n")
 			}
+			if err := diffPackage(diffFlags.Arg(0), diffFlags.Arg(1), noPathValidation, *n); err != nil {
+				log.Fatalf("failed to diff package: %s\n", err)
+			}
+
+			exit()
+		}
+	case "fmt":
+		{
+			fmtFlags := flag.NewFlagSet("fmt", flag.ExitOnError)
+			check := fmtFlags.Bool("check", false, "If set, files are not rewritten and an error is reported for each file which isn't formatted.")
+			fmtFlags.Parse(flag.Args()[1:])
+
+			for _, path := range fmtFlags.Args() {
+				if err := formatPackage(path, *check); err != nil {
+					log.Fatalf("failed to format package: %s\n", err)
+				}
+			}
+
+			exit()
+		}
+	case "migrate":
+		{
+			migrateFlags := flag.NewFlagSet("migrate", flag.ExitOnError)
+			dryRun := migrateFlags.Bool("dry-run", false, "If set, files are not rewritten and the diffs are printed.")
+			list := migrateFlags.Bool("list", false, "If set, the migrations are listed.")
+			from := migrateFlags.Int("from", 0, "Only apply the migrations after this version.")
+			to := migrateFlags.Int("to", packages.LatestMigration(), "Only apply the migrations up to this version.")
+			migrateFlags.Parse(flag.Args()[1:])
+
+			if *list {
+				printMigrations()
+				return
+			}
+
+			files, err := listPackageFiles(migrateFlags.Args())
+			if err != nil {
+				log.Fatalf("failed to list packages: %s\n", err)
+			}
+			for _, path := range files {
+				if err := migratePackage(path, *from, *to, *dryRun); err != nil {
+					log.Fatalf("failed to migrate package: %s\n", err)
+				}
+			}
+
+			exit()
 		}
 	default:
 		panic(fmt.Sprintf("unknown subcommand: `%s`", subcommand))
 	}
 }
 
-func processVersion(ctx context.Context, pckg *packages.Package, v version.Version) (string, error) {
+// The result of processing a version with the fileMap of a package.
+type processedFiles struct {
+	files []PublishedFile
+	// files which aren't published since their destination is already used
+	collisions []packages.DestinationCollision
+	// files which look sensitive, published unless blocked
+	sensitive []secrets.Finding
+}
+
+// Downloads and processes a version, adding the files
+// matched by each pattern to the report if any.
+func processVersion(ctx context.Context, pckg *packages.Package, v version.Version, report *patternReport) (processedFiles, error) {
+	buff, err := version.DownloadTar(ctx, v)
+	if err != nil {
+		return processedFiles{}, errors.Wrap(err, "failed to download version")
+	}
+	if report != nil {
+		if err := report.add(ctx, v.Version, buff.Bytes()); err != nil {
+			return processedFiles{}, errors.Wrap(err, "failed to match patterns")
+		}
+	}
+	return processTarball(ctx, pckg, v, buff.Bytes())
+}
+
+// Processes the downloaded tarball of a version, in the sandbox unless
+// disabled, returning the files published and the ones which aren't.
+func processTarball(ctx context.Context, pckg *packages.Package, v version.Version, tarball []byte) (processedFiles, error) {
+	if noSandbox {
+		return processTarballInProcess(pckg, tarball)
+	}
+
+	outDir, logs, err := runSandbox(ctx, pckg, v, tarball)
+	defer os.RemoveAll(outDir)
+	if err != nil {
+		return processedFiles{}, err
+	}
+
+	names, err := listOutputFiles(outDir)
+	if err != nil {
+		return processedFiles{}, err
+	}
+	files := make([]PublishedFile, 0, len(names))
+	for _, name := range names {
+		f, err := outputFileSizes(outDir, name)
+		if err != nil {
+			return processedFiles{}, err
+		}
+		files = append(files, f)
+	}
+	return processedFiles{
+		files:      files,
+		collisions: packages.ParseDestinationCollisions(logs),
+		sensitive:  secrets.ParseFindings(logs),
+	}, nil
+}
+
+// Runs the sandbox on the tarball of a version, returning
+// the output directory and the logs of the sandbox.
+func runSandbox(ctx context.Context, pckg *packages.Package, v version.Version, tarball []byte) (string, string, error) {
 	inDir, outDir, err := sandbox.Setup()
 	if err != nil {
-		return outDir, errors.Wrap(err, "failed to setup sandbox")
+		return outDir, "", errors.Wrap(err, "failed to setup sandbox")
 	}
 	defer os.RemoveAll(inDir)
 
-	buff := version.DownloadTar(ctx, v)
-
 	dst, err := os.Create(path.Join(inDir, "new-version.tgz"))
 	if err != nil {
-		return outDir, errors.Wrap(err, "could not write tmp file")
+		return outDir, "", errors.Wrap(err, "could not write tmp file")
 	}
 	defer dst.Close()
-	if _, err := dst.Write(buff.Bytes()); err != nil {
-		return outDir, errors.Wrap(err, "could not write new version in sandbox")
+	if _, err := dst.Write(tarball); err != nil {
+		return outDir, "", errors.Wrap(err, "could not write new version in sandbox")
 	}
 
 	if err := writeConfig(inDir, pckg); err != nil {
-		return outDir, errors.Wrap(err, "failed to write configuration")
+		return outDir, "", errors.Wrap(err, "failed to write configuration")
 	}
 
 	name := fmt.Sprintf("%s_%s", *pckg.Name, v.Version)
 	logs, err := sandbox.Run(ctx, name, inDir, outDir)
 	if err != nil {
-		return outDir, errors.Wrap(err, "failed to run sandbox")
+		return outDir, "", errors.Wrap(err, "failed to run sandbox")
 	}
 	log.Println("logs", len(logs), logs)
 
-	return outDir, nil
+	return outDir, logs, nil
 }
 
-func showFiles(pckgPath string, noPathValidation bool) error {
+// Extracts the tarball of a version and applies the fileMap of the package
+// directly, without minifying the files, as an alternative to the sandbox.
+func processTarballInProcess(pckg *packages.Package, tarball []byte) (processedFiles, error) {
+	dir, err := ioutil.TempDir("", "version")
+	if err != nil {
+		return processedFiles{}, errors.Wrap(err, "failed to create version directory")
+	}
+	defer os.RemoveAll(dir)
+
+	if err := packages.ExtractTarball(bytes.NewReader(tarball), *pckg.Autoupdate.Source, dir); err != nil {
+		return processedFiles{}, errors.Wrap(err, "failed to extract version")
+	}
+
+	return processDir(pckg, dir)
+}
+
+// Applies the fileMap of the package to the files of a directory,
+// leaving out the files which look sensitive like process-version.
+func processDir(pckg *packages.Package, dir string) (processedFiles, error) {
+	ops, collisions := pckg.NpmFilesAndCollisionsFrom(dir)
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].To < ops[j].To
+	})
+
+	// files overwritten by a later one, when no fileMap has a destination
+	overwritten := make(map[string]bool)
+	for _, collision := range collisions {
+		overwritten[collision.From] = true
+	}
+
+	res := processedFiles{
+		files:      make([]PublishedFile, 0, len(ops)),
+		collisions: collisions,
+	}
+	for _, op := range ops {
+		if overwritten[op.From] {
+			continue
+		}
+		finding, err := secrets.ScanFile(path.Join(dir, op.From), op.From)
+		if err != nil {
+			return processedFiles{}, err
+		}
+		if finding != nil {
+			res.sensitive = append(res.sensitive, *finding)
+			if finding.Blocked {
+				continue
+			}
+		}
+
+		f, err := dirFileSizes(dir, op.From, op.To)
+		if err != nil {
+			return processedFiles{}, err
+		}
+		res.files = append(res.files, f)
+	}
+	return res, nil
+}
+
+func printNoSandbox() {
+	printf("\nfiles listed without the sandbox, minified files are not included\n")
+}
+
+// Pulls the image of the sandbox, unless it is disabled.
+func initSandbox(ctx context.Context) {
+	if noSandbox {
+		return
+	}
+	if err := sandbox.Init(ctx); err != nil {
+		log.Fatalf("failed to init sandbox: %s", err)
+	}
+}
+
+// The files listed by show-files instead of the
+// ones of the last versions of a package, if any.
+type showFilesTarget struct {
+	version string
+	tarball string
+	dir     string
+}
+
+// Whether at most one of the targets is set.
+func (t showFilesTarget) valid() bool {
+	var n int
+	for _, s := range []string{t.version, t.tarball, t.dir} {
+		if s != "" {
+			n++
+		}
+	}
+	return n <= 1
+}
+
+func showFiles(pckgPath string, noPathValidation bool, target showFilesTarget) error {
 	// create context with file path prefix, checker logger
 	ctx := util.ContextWithEntries(util.GetCheckerEntries(pckgPath, logger)...)
 
 	// parse *Package from JSON
-	pckg, err := parseHumanPackage(ctx, pckgPath, noPathValidation)
+	pckg, err := parseHumanPackage(ctx, "", pckgPath, noPathValidation)
 	if err != nil {
 		return errors.Wrap(err, "could not parse package")
 	}
@@ -112,20 +367,94 @@ func showFiles(pckgPath string, noPathValidation bool) error {
 		return nil
 	}
 
-	if err := sandbox.Init(ctx); err != nil {
-		log.Fatalf("failed to init sandbox: %s", err)
+	output.ShowFiles = &ShowFilesResult{
+		Package:          *pckg.Name,
+		VersionRange:     pckg.Autoupdate.VersionRange,
+		PrereleasePolicy: pckg.Autoupdate.PrereleasePolicy(),
+		Versions:         []VersionFiles{},
+	}
+
+	// a directory is already extracted, so it is never processed in the sandbox
+	if target.dir != "" {
+		printNoSandbox()
+		return printDirectory(ctx, pckg, target.dir)
+	}
+
+	initSandbox(ctx)
+
+	if target.tarball != "" {
+		if noSandbox {
+			printNoSandbox()
+		}
+		return printTarball(ctx, pckg, target.tarball)
 	}
 
 	// autoupdate exists, download latest versions based on source
 	src := *pckg.Autoupdate.Source
 
+	versions, err := listVersions(ctx, pckg)
+	if err != nil {
+		return err
+	}
+
+	if r := pckg.Autoupdate.VersionRange; r != nil {
+		printf("\nversions restricted to range `%s`\n", *r)
+	}
+	if policy := pckg.Autoupdate.PrereleasePolicy(); policy != packages.PrereleaseAll {
+		printf("\nprerelease policy: `%s`\n", policy)
+	}
+	if noSandbox {
+		printNoSandbox()
+	}
+
+	if target.version != "" {
+		for _, v := range versions {
+			if v.Version == target.version {
+				return printVersion(ctx, pckg, "version", v, nil)
+			}
+		}
+		show(ctx, noVersion, fmt.Sprintf("version `%s` not found on %s", target.version, src))
+		return nil
+	}
+
+	// download into temp dir
+	if len(versions) > 0 {
+		// print info for first src version
+		report := newPatternReport(pckgPath, pckg)
+		if err := printMostRecentVersion(ctx, pckg, versions[0], report); err != nil {
+			return errors.Wrap(err, "could not print most recent version")
+		}
+
+		// print aggregate info for the few last src versions
+		if err := printLastVersions(ctx, pckg, versions[1:], report); err != nil {
+			return errors.Wrap(err, "could not print most last versions")
+		}
+
+		printChanges()
+		report.print()
+	} else {
+		show(ctx, noVersion, "no version found on "+src)
+	}
+	return nil
+}
+
+// Lists the versions of a package from its autoupdate source,
+// the most recent first.
+func listVersions(ctx context.Context, pckg *packages.Package) ([]version.Version, error) {
 	var versions []version.Version
 
-	switch src {
+	switch src := *pckg.Autoupdate.Source; src {
 	case "npm":
 		{
 			// get npm versions and sort
-			versions, _ = npm.GetVersions(ctx, pckg.Autoupdate)
+			var err error
+			versions, _, err = npmClient.GetVersions(ctx, pckg.Autoupdate)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to retrieve npm versions")
+			}
+			if err := npmClient.DateVersions(ctx, *pckg.Autoupdate.Target, versions); err != nil {
+				return nil, errors.Wrap(err, "failed to date npm versions")
+			}
 			sort.Sort(version.ByDate(versions))
 		}
 	case "git":
@@ -134,7 +463,7 @@ func showFiles(pckgPath string, noPathValidation bool) error {
 			// get git versions and sort
 			versions, err = git.GetVersions(ctx, pckg.Autoupdate)
 			if err != nil {
-				return errors.Wrap(err, "failed to retrieve git versions")
+				return nil, errors.Wrap(err, "failed to retrieve git versions")
 			}
 			sort.Sort(version.ByDate(versions))
 		}
@@ -143,32 +472,18 @@ func showFiles(pckgPath string, noPathValidation bool) error {
 			panic(fmt.Sprintf("unknown autoupdate source: %s", src))
 		}
 	}
-
-	// download into temp dir
-	if len(versions) > 0 {
-		// print info for first src version
-		if err := printMostRecentVersion(ctx, pckg, versions[0]); err != nil {
-			return errors.Wrap(err, "could not print most recent version")
-		}
-
-		// print aggregate info for the few last src versions
-		if err := printLastVersions(ctx, pckg, versions[1:]); err != nil {
-			return errors.Wrap(err, "could not print most last versions")
-		}
-	} else {
-		showErr(ctx, "no version found on "+src)
-	}
-	return nil
+	return versions, nil
 }
 
 // Try to parse a *Package, outputting ci errors/warnings.
-// If there is an issue, *Package will be nil.
-func parseHumanPackage(ctx context.Context, pckgPath string, noPathValidation bool) (*packages.Package, error) {
+// If there is an issue, *Package will be nil. The path is
+// validated as is, and the file is read relative to root.
+func parseHumanPackage(ctx context.Context, root, pckgPath string, noPathValidation bool) (*packages.Package, error) {
 	if !noPathValidation {
 		// check package path matches regex
 		matches := pckgPathRegex.FindStringSubmatch(pckgPath)
 		if matches == nil {
-			showErr(ctx, fmt.Sprintf("package path `%s` does not match %s", pckgPath, pckgPathRegex.String()))
+			show(ctx, invalidPath, fmt.Sprintf("package path `%s` does not match %s", pckgPath, pckgPathRegex.String()))
 			return nil, nil
 		}
 
@@ -177,32 +492,29 @@ func parseHumanPackage(ctx context.Context, pckgPath string, noPathValidation bo
 		actualDir, pckgName := matches[1], matches[2]
 		expectedDir := strings.ToLower(string(pckgName[0]))
 		if actualDir != expectedDir {
-			showErr(ctx, fmt.Sprintf("package `%s` must go into `%s` dir, not `%s` dir", pckgName, expectedDir, actualDir))
+			show(ctx, wrongDirectory, fmt.Sprintf("package `%s` must go into `%s` dir, not `%s` dir", pckgName, expectedDir, actualDir))
 			return nil, nil
 		}
 	}
 
-	bytes, err := ioutil.ReadFile(pckgPath)
+	bytes, err := ioutil.ReadFile(filepath.Join(root, pckgPath))
 	if err != nil {
-		showErr(ctx, "failed to read")
+		show(ctx, readFailed, "failed to read")
 		return nil, errors.Wrap(err, "failed to read package file")
 	}
 
 	// parse package JSON
 	pckg, readerr := packages.ReadHumanJSONBytes(ctx, pckgPath, bytes, true)
 	if readerr != nil {
-		if invalidHumanErr, ok := readerr.(packages.InvalidSchemaError); ok {
-			// output all schema errors
-			for _, resErr := range invalidHumanErr.Result.Errors() {
-				showErr(ctx, resErr.String())
-			}
-		} else {
-			showErr(ctx, readerr.Error())
-		}
+		showReadErr(ctx, bytes, readerr)
 		return nil, nil
 	}
 
+	suppress(ctx, pckgPath, bytes, pckg)
 	checkFilename(ctx, pckg)
+	if !checkVersionRange(ctx, pckg) {
+		return nil, nil
+	}
 	return pckg, nil
 }
 
@@ -221,135 +533,316 @@ func filewalker(basedir string, files *[]string) filepath.WalkFunc {
 	}
 }
 
+// Lists the files published from the output directory of the sandbox.
+func listOutputFiles(outDir string) ([]string, error) {
+	files := make([]string, 0)
+	if err := filepath.Walk(outDir, filewalker(outDir, &files)); err != nil {
+		return nil, errors.Wrap(err, "could not inspect sandbox output")
+	}
+	return files, nil
+}
+
 // Prints the files of a package version, outputting debug
 // messages if no valid files are present.
-func printMostRecentVersion(ctx context.Context, p *packages.Package, v version.Version) error {
-	fmt.Printf("\nmost recent version: %s\n", v.Version)
+func printMostRecentVersion(ctx context.Context, p *packages.Package, v version.Version, report *patternReport) error {
+	return printVersion(ctx, p, "most recent version", v, report)
+}
+
+// Prints the files of a package version, described by a label.
+func printVersion(ctx context.Context, p *packages.Package, label string, v version.Version, report *patternReport) error {
+	printf("\n%s: %s\n", label, v.Version)
 
-	outDir, err := processVersion(ctx, p, v)
+	res, err := processVersion(ctx, p, v, report)
 	if err != nil {
 		log.Fatalf("failed to process version: %s", err)
 	}
-	defer os.RemoveAll(outDir)
 
-	var files []string
+	printFiles(ctx, p, label, "version", v.Version, res)
+	return nil
+}
 
-	err = filepath.Walk(outDir, filewalker(outDir, &files))
+// Prints the files of a local tarball.
+func printTarball(ctx context.Context, p *packages.Package, tarball string) error {
+	printf("\ntarball: %s\n", tarball)
+
+	bytes, err := ioutil.ReadFile(tarball)
+	if err != nil {
+		return errors.Wrap(err, "failed to read tarball")
+	}
+	v := version.Version{Version: strings.TrimSuffix(filepath.Base(tarball), ".tgz")}
+	res, err := processTarball(ctx, p, v, bytes)
 	if err != nil {
-		return errors.Wrap(err, "could not inspect sandbox output")
+		return errors.Wrap(err, "failed to process tarball")
 	}
 
-	if len(files) == 0 {
-		errormsg := fmt.Sprintf("No files will be published for version %s.\n", v.Version)
-		showErr(ctx, errormsg)
-		return nil
+	printFiles(ctx, p, "tarball", "tarball", tarball, res)
+	return nil
+}
+
+// Prints the files of a local unpacked directory.
+func printDirectory(ctx context.Context, p *packages.Package, dir string) error {
+	printf("\ndirectory: %s\n", dir)
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return errors.Errorf("`%s` is not a directory", dir)
+	}
+	res, err := processDir(p, dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to process directory")
 	}
 
-	var filenameFound bool
+	printFiles(ctx, p, "directory", "directory", dir, res)
+	return nil
+}
 
-	fmt.Printf("\n```\n")
-	for _, file := range files {
-		fmt.Printf("%s\n", file)
-		if p.Filename != nil && !filenameFound && file == *p.Filename {
-			filenameFound = true
+// Prints the files published from a source, a version, a tarball or
+// a directory, described by a label in the diagnostics about the filename
+// and by its kind in the other ones.
+func printFiles(ctx context.Context, p *packages.Package, label, kind, name string, res processedFiles) {
+	// files from different fileMaps published at the same destination
+	for _, collision := range res.collisions {
+		show(ctx, fileCollision, fmt.Sprintf("%s in %s %s", collision, kind, name))
+	}
+	for _, finding := range res.sensitive {
+		check := sensitiveFile
+		if !finding.Blocked {
+			check = possibleSecret
 		}
+		show(ctx, check, fmt.Sprintf("%s in %s %s", finding, kind, name))
 	}
-	fmt.Printf("```\n")
+	files := res.files
+	output.ShowFiles.Versions = append(output.ShowFiles.Versions, newVersionFiles(name, files))
 
-	if p.Filename != nil && !filenameFound {
-		showErr(ctx, fmt.Sprintf("Filename `%s` not found in most recent version `%s`.\n", *p.Filename, v.Version))
+	if len(files) == 0 {
+		errormsg := fmt.Sprintf("No files will be published for %s %s.\n", kind, name)
+		show(ctx, noFiles, errormsg)
+		return
+	}
+
+	printSizes(files)
+
+	if p.Filename != nil && !containsFilename(fileNames(files), p.Filename) {
+		show(ctx, filenameNotFound, fmt.Sprintf("Filename `%s` not found in %s `%s`.\n", *p.Filename, label, name))
 	}
-	return nil
 }
 
 // Prints the matching files of a number of last versions.
 // Each previous version will be downloaded and cleaned up if necessary.
 // For example, a temporary directory may be downloaded and then removed later.
-func printLastVersions(ctx context.Context, p *packages.Package, versions []version.Version) error {
+func printLastVersions(ctx context.Context, p *packages.Package, versions []version.Version, report *patternReport) error {
 	// limit versions
 	if len(versions) > util.ImportAllMaxVersions {
 		versions = versions[:util.ImportAllMaxVersions]
 	}
 
-	fmt.Printf("\n%d last version(s):\n", len(versions))
+	printf("\n%d last version(s):\n", len(versions))
 	for _, version := range versions {
-		outDir, err := processVersion(ctx, p, version)
+		res, err := processVersion(ctx, p, version, report)
 		if err != nil {
 			log.Fatalf("failed to process version: %s", err)
 		}
+		files := res.files
+		output.ShowFiles.Versions = append(output.ShowFiles.Versions, newVersionFiles(version.Version, files))
 
-		var files []string
-
-		err = filepath.Walk(outDir, filewalker(outDir, &files))
-		if err != nil {
-			return errors.Wrap(err, "could not inspect sandbox output")
-		}
-
-		fmt.Printf("- %s: %d file(s) matched", version.Version, len(files))
+		printf("- %s: %d file(s) matched", version.Version, len(files))
 		if len(files) > 0 {
-			fmt.Printf(" :heavy_check_mark:\n")
+			printf(", %s :heavy_check_mark:\n", formatSize(totalSize(files)))
 		} else {
-			fmt.Printf(" :heavy_exclamation_mark:\n")
+			printf(" :heavy_exclamation_mark:\n")
 		}
-
-		os.RemoveAll(outDir)
 	}
 	return nil
 }
 
-func checkGitHubPopularity(ctx context.Context, pckg *packages.Package) bool {
+// Prints the files added and removed by the most recent version
+// since the previous one, if both were listed.
+func printChanges() {
+	if len(output.ShowFiles.Versions) < 2 {
+		return
+	}
+	latest, previous := output.ShowFiles.Versions[0], output.ShowFiles.Versions[1]
+	added, removed := diffFiles(previous.Files, latest.Files)
+	output.ShowFiles.Changes = &VersionChanges{
+		Version:  latest.Version,
+		Previous: previous.Version,
+		Added:    added,
+		Removed:  removed,
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		printf("\nno file added or removed since %s\n", previous.Version)
+		return
+	}
+	printf("\nsince %s: %d file(s) added, %d file(s) removed\n", previous.Version, len(added), len(removed))
+	for _, file := range added {
+		printf("  + %s\n", file)
+	}
+	for _, file := range removed {
+		printf("  - %s :heavy_exclamation_mark:\n", file)
+	}
+}
+
+// Checks that the GitHub repository of a package, if any, is popular.
+// If the rate limit of GitHub is hit, the repository is assumed to be.
+func checkGitHubPopularity(ctx context.Context, pckg *packages.Package) (bool, error) {
 	if !strings.Contains(*pckg.Repository.URL, "github.com") {
-		return false
+		return false, nil
 	}
 
-	if s := git.GetGitHubStars(*pckg.Repository.URL); s.Stars < util.MinGitHubStars {
-		showWarn(ctx, fmt.Sprintf("stars on GitHub is under %d", util.MinGitHubStars))
+	s, err := git.GetGitHubStars(*pckg.Repository.URL)
+	if err != nil {
+		if rateLimited(ctx, err) {
+			return true, nil
+		}
+		return false, errors.Wrap(err, "could not get GitHub stars")
+	}
+	if s.Stars < util.MinGitHubStars {
+		show(ctx, lowGitHubStars, fmt.Sprintf("stars on GitHub is under %d", util.MinGitHubStars))
+		return false, nil
+	}
+	return true, nil
+}
+
+// Outputs a warning if a lookup failed because a rate limit was hit,
+// in which case the check is skipped, and returns whether it was.
+func rateLimited(ctx context.Context, err error) bool {
+	if _, ok := errors.Cause(err).(util.RateLimitError); !ok {
 		return false
 	}
+	show(ctx, rateLimit, "check skipped: "+err.Error())
 	return true
 }
 
 func checkFilename(ctx context.Context, pckg *packages.Package) {
 	// warn if filename is not present
-	// current, only a few packages have exceptions
-	// that allow them to have missing filenames
+	// currently, only a few packages have exceptions
+	// that allow them to have missing filenames,
+	// which suppress the warning
 	if pckg.Filename == nil {
-		showWarn(ctx, "filename is missing")
+		show(ctx, missingFilename, "filename is missing")
 	}
 }
 
-func lintPackage(pckgPath string, noPathValidation bool) error {
-	// create context with file path prefix, checker logger
-	ctx := util.ContextWithEntries(util.GetCheckerEntries(pckgPath, logger)...)
+// Checks that the version range is valid, since versions can't
+// be listed otherwise.
+func checkVersionRange(ctx context.Context, pckg *packages.Package) bool {
+	if pckg.Autoupdate.VersionRange == nil {
+		return true
+	}
+	if _, err := version.ParseRange(*pckg.Autoupdate.VersionRange); err != nil {
+		show(ctx, invalidVersionRange, err.Error())
+		return false
+	}
+	return true
+}
 
-	// parse *Package from JSON
-	pckg, err := parseHumanPackage(ctx, pckgPath, noPathValidation)
-	if err != nil {
-		return errors.Wrap(err, "could not parse package")
+// Checks that the license, if any, is a valid SPDX expression of licenses
+// approved by the OSI, and that it matches the license of the latest version
+// on npm, or the license of the repository for git sources on GitHub.
+func checkLicense(ctx context.Context, pckg *packages.Package) error {
+	if pckg.License == nil {
+		return nil
 	}
-	if pckg == nil {
+	license, err := spdx.Parse(*pckg.License)
+	if err != nil {
+		show(ctx, invalidLicense, fmt.Sprintf("license `%s` isn't a valid SPDX expression: %s", *pckg.License, err))
 		return nil
 	}
+	if !license.OSIApproved() {
+		show(ctx, nonOSILicense, fmt.Sprintf("license `%s` isn't approved by the OSI", *pckg.License))
+	}
+
+	switch *pckg.Autoupdate.Source {
+	case "npm":
+		upstream, err := npmClient.GetLicense(*pckg.Autoupdate.Target)
+		if err != nil {
+			if rateLimited(ctx, err) {
+				return nil
+			}
+			return errors.Wrap(err, "could not get npm license")
+		}
+		// licenses which aren't SPDX expressions, ex. `SEE LICENSE IN LICENSE.md`, can't be compared
+		if upstreamLicense, err := spdx.Parse(upstream); err == nil && !license.Equal(upstreamLicense) {
+			show(ctx, licenseMismatch, fmt.Sprintf("license `%s` doesn't match `%s` declared by the latest version on npm", *pckg.License, upstream))
+		}
+	case "git":
+		if !strings.Contains(*pckg.Repository.URL, "github.com") {
+			return nil
+		}
+		upstream, err := git.GetGitHubLicense(*pckg.Repository.URL)
+		if err != nil {
+			if rateLimited(ctx, err) {
+				return nil
+			}
+			return errors.Wrap(err, "could not get GitHub license")
+		}
+		// GitHub detects a single license, so it only needs to be part of the expression
+		if upstream != "" && !containsLicense(license.Licenses(), upstream) {
+			show(ctx, licenseMismatch, fmt.Sprintf("license `%s` doesn't include `%s` detected in the GitHub repository", *pckg.License, upstream))
+		}
+	}
+	return nil
+}
+
+func containsLicense(licenses []string, license string) bool {
+	for _, l := range licenses {
+		if strings.EqualFold(strings.TrimSuffix(l, "+"), license) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lints a package file relative to root, returning it unless it is invalid.
+func lintPackage(root, pckgPath string, noPathValidation bool) (*packages.Package, error) {
+	// create context with file path prefix, checker logger
+	ctx := util.ContextWithEntries(util.GetCheckerEntries(pckgPath, logger)...)
+
+	// parse *Package from JSON, a file which can't be read is already reported
+	pckg, err := parseHumanPackage(ctx, root, pckgPath, noPathValidation)
+	if err != nil || pckg == nil {
+		return nil, nil
+	}
 
 	switch *pckg.Autoupdate.Source {
 	case "npm":
 		{
 			// check that it exists
-			if !npm.Exists(*pckg.Autoupdate.Target) {
-				showErr(ctx, "package doesn't exist on npm")
+			exists, err := npmClient.Exists(*pckg.Autoupdate.Target)
+			if err != nil {
+				if rateLimited(ctx, err) {
+					break
+				}
+				return nil, errors.Wrap(err, "could not check npm package")
+			}
+			if !exists {
+				show(ctx, npmNotFound, "package doesn't exist on npm")
 				break
 			}
 
 			// check if it has enough downloads
-			if md := npm.GetMonthlyDownload(*pckg.Autoupdate.Target); md.Downloads < util.MinNpmMonthlyDownloads {
-				if !checkGitHubPopularity(ctx, pckg) {
-					showWarn(ctx, fmt.Sprintf("package download per month on npm is under %d", util.MinNpmMonthlyDownloads))
+			md, err := npmClient.GetMonthlyDownload(*pckg.Autoupdate.Target)
+			if err != nil {
+				if rateLimited(ctx, err) {
+					break
+				}
+				return nil, errors.Wrap(err, "could not get npm downloads")
+			}
+			if md.Downloads < util.MinNpmMonthlyDownloads {
+				popular, err := checkGitHubPopularity(ctx, pckg)
+				if err != nil {
+					return nil, err
+				}
+				if !popular {
+					show(ctx, lowNpmDownloads, fmt.Sprintf("package download per month on npm is under %d", util.MinNpmMonthlyDownloads))
 				}
 			}
 		}
 	case "git":
 		{
-			checkGitHubPopularity(ctx, pckg)
+			if _, err := checkGitHubPopularity(ctx, pckg); err != nil {
+				return nil, err
+			}
 		}
 	default:
 		{
@@ -358,19 +851,109 @@ func lintPackage(pckgPath string, noPathValidation bool) error {
 		}
 	}
 
+	if err := checkLicense(fieldContext(root, pckgPath, "license"), pckg); err != nil {
+		return nil, err
+	}
+
 	log.Printf("%s lint OK\n", pckgPath)
-	return nil
+	return pckg, nil
+}
+
+// Lints package files relative to root, up to parallel at a time, returning
+// the valid packages in the order of their files. A package which can't be
+// linted, ex. when a lookup fails, is reported and the others are linted.
+func lintPackages(root string, files []string, noPathValidation bool, parallel int) []lintedPackage {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	pckgs := make([]*packages.Package, len(files))
+	errs := make([]error, len(files))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pckgs[i], errs[i] = lintPackage(root, file, noPathValidation)
+		}(i, file)
+	}
+	wg.Wait()
+
+	var linted []lintedPackage
+	for i, file := range files {
+		if errs[i] != nil {
+			ctx := util.ContextWithEntries(util.GetCheckerEntries(file, logger)...)
+			show(ctx, lintFailed, "failed to lint: "+errs[i].Error())
+			continue
+		}
+		if pckgs[i] != nil {
+			linted = append(linted, lintedPackage{file, pckgs[i]})
+		}
+	}
+	return linted
 }
 
-// wrapper around outputting a checker error
-func showErr(ctx context.Context, s string) {
-	util.Errf(ctx, s)
-	errCount++
+// Outputs the errors of reading a package, at their position in
+// src if known. Schema errors can be numerous, one per field.
+func showReadErr(ctx context.Context, src []byte, err error) {
+	if invalidHumanErr, ok := err.(packages.InvalidSchemaError); ok {
+		// output all schema errors
+		for _, resErr := range invalidHumanErr.Result.Errors() {
+			msg := resErr.String()
+			if resErr.Field() == "name" && resErr.Type() == "pattern" {
+				msg += nameSuggestion(src)
+			}
+			show(schemaErrContext(ctx, src, resErr), invalidSchema, msg)
+		}
+		return
+	}
+
+	if syntaxErr, ok := errors.Cause(err).(*json.SyntaxError); ok && src != nil && syntaxErr.Offset > 0 {
+		// the offset is after the invalid character
+		ctx = context.WithValue(ctx, util.Position, util.PositionOf(src, int(syntaxErr.Offset)-1))
+	}
+	show(ctx, invalidJSON, err.Error())
 }
 
-// wrapper around outputting a checker warning
-func showWarn(ctx context.Context, s string) {
-	util.Warnf(ctx, s)
+// Suggests a valid library name for a package with an invalid name,
+// mapped from its scoped npm target if any, ex. `scope-name` for `@scope/name`.
+func nameSuggestion(src []byte) string {
+	var p packages.Package
+	if err := json.Unmarshal(src, &p); err != nil {
+		return ""
+	}
+	name := packages.SuggestedName(&p)
+	if name == "" {
+		return ""
+	}
+	if target, ok := packages.ScopedNpmTarget(&p); ok {
+		return fmt.Sprintf(", ex. `%s` for the scoped npm package `%s`", name, target)
+	}
+	return fmt.Sprintf(", ex. `%s`", name)
+}
+
+// Returns the context of a schema error, positioned
+// at the key or item of its field in src.
+func schemaErrContext(ctx context.Context, src []byte, resErr gojsonschema.ResultError) context.Context {
+	field := resErr.Field()
+	if resErr.Type() == "additional_property_not_allowed" {
+		// the error is on the object, but the property is more helpful
+		if property, ok := resErr.Details()["property"].(string); ok {
+			if field == "(root)" {
+				field = property
+			} else {
+				field += "." + property
+			}
+		}
+	}
+
+	if pos, ok := util.JSONFieldPosition(src, field); ok {
+		return context.WithValue(ctx, util.Position, pos)
+	}
+	return ctx
 }
 
 func writeConfig(dstDir string, pkg *packages.Package) error {
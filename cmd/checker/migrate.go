@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Lists the package files of paths, which are either
+// package files or directories containing them.
+func listPackageFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		err := filepath.Walk(p, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return errors.Wrap(err, "failed to walk fs")
+			}
+			if !info.IsDir() && (file == p || filepath.Ext(file) == ".json") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Applies the migrations between from and to a package file,
+// printing the diff instead of rewriting the file in dry-run mode.
+func migratePackage(pckgPath string, from, to int, dryRun bool) error {
+	// create context with file path prefix, checker logger
+	ctx := util.ContextWithEntries(util.GetCheckerEntries(pckgPath, logger)...)
+
+	src, err := ioutil.ReadFile(pckgPath)
+	if err != nil {
+		show(ctx, readFailed, "failed to read")
+		return errors.Wrap(err, "failed to read package file")
+	}
+
+	migrated, applied, err := packages.MigrateHumanJSONBytes(ctx, pckgPath, src, from, to)
+	if err != nil {
+		if len(applied) > 0 {
+			show(ctx, invalidMigration, "package is invalid after migrations "+describeMigrations(applied))
+		}
+		// the errors are in the migrated package, not in the file
+		showReadErr(ctx, nil, err)
+		return nil
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	if dryRun {
+		printMigrationDiff(pckgPath, applied, src, migrated)
+		return nil
+	}
+
+	if err := ioutil.WriteFile(pckgPath, migrated, 0644); err != nil {
+		return errors.Wrap(err, "failed to write package file")
+	}
+	for _, m := range applied {
+		log.Printf("%s: applied migration %d (%s)\n", pckgPath, m.Version, m.Description)
+	}
+	return nil
+}
+
+func describeMigrations(migrations []packages.Migration) string {
+	var descriptions []string
+	for _, m := range migrations {
+		descriptions = append(descriptions, fmt.Sprintf("%d (%s)", m.Version, m.Description))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+func printMigrationDiff(pckgPath string, applied []packages.Migration, src, migrated []byte) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(src)),
+		B:        difflib.SplitLines(string(migrated)),
+		FromFile: pckgPath,
+		ToFile:   pckgPath,
+		Context:  3,
+	})
+	util.Check(err)
+
+	printf("migrations %s\n%s\n", describeMigrations(applied), diff)
+}
+
+// Lists the migrations.
+func printMigrations() {
+	for _, m := range packages.Migrations {
+		fmt.Printf("%d: %s\n", m.Version, m.Description)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/cdnjs/tools/util"
+)
+
+// Output formats of the checker.
+const (
+	// GitHub workflow commands and free text
+	formatGitHub = "github"
+	// a JSON document with the diagnostics and the results
+	formatJSON = "json"
+	// a SARIF log for code scanning
+	formatSARIF = "sarif"
+)
+
+// Diagnostic is an error or warning about a package file.
+// A suppressed warning has the justification of its suppression.
+type Diagnostic struct {
+	Severity    string  `json:"severity"`
+	Code        string  `json:"code"`
+	File        string  `json:"file"`
+	Line        int     `json:"line"`
+	Col         int     `json:"col"`
+	Message     string  `json:"message"`
+	Suppression *string `json:"suppression,omitempty"`
+}
+
+// Output is the document printed with the json format.
+type Output struct {
+	Diagnostics []Diagnostic     `json:"diagnostics"`
+	ShowFiles   *ShowFilesResult `json:"showFiles,omitempty"`
+	Summary     *Summary         `json:"summary,omitempty"`
+	Diff        *DiffResult      `json:"diff,omitempty"`
+}
+
+// Summary counts the diagnostics of the packages linted by lint-all.
+type Summary struct {
+	Packages   int            `json:"packages"`
+	Errors     int            `json:"errors"`
+	Warnings   int            `json:"warnings"`
+	Suppressed int            `json:"suppressed"`
+	Codes      map[string]int `json:"codes"`
+}
+
+// ShowFilesResult lists the files published for the last versions of a package.
+type ShowFilesResult struct {
+	Package          string          `json:"package"`
+	VersionRange     *string         `json:"versionRange,omitempty"`
+	PrereleasePolicy string          `json:"prereleasePolicy"`
+	Versions         []VersionFiles  `json:"versions"`
+	Changes          *VersionChanges `json:"changes,omitempty"`
+	Patterns         []PatternResult `json:"patterns,omitempty"`
+}
+
+// VersionFiles are the files published for a version, and their sizes.
+type VersionFiles struct {
+	Version   string          `json:"version"`
+	Files     []string        `json:"files"`
+	Sizes     []PublishedFile `json:"sizes"`
+	TotalSize int64           `json:"totalSize"`
+}
+
+func newVersionFiles(version string, files []PublishedFile) VersionFiles {
+	return VersionFiles{
+		Version:   version,
+		Files:     fileNames(files),
+		Sizes:     files,
+		TotalSize: totalSize(files),
+	}
+}
+
+// VersionChanges are the files added and removed by
+// the most recent version since the previous one.
+type VersionChanges struct {
+	Version  string   `json:"version"`
+	Previous string   `json:"previous"`
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+}
+
+var (
+	// Output format, set by the -format flag
+	outputFormat = formatGitHub
+
+	// Guards the output, since packages are linted in parallel
+	outputMu sync.Mutex
+	output   = Output{Diagnostics: []Diagnostic{}}
+)
+
+// Records a diagnostic, counting the errors, and outputs it directly with
+// the github format, which only logs suppressed warnings with their justification.
+func show(ctx context.Context, c Check, s string) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	file, _ := ctx.Value(util.LoggerPrefix).(string)
+	justification, suppressed := suppressionOf(file, c)
+	if !suppressed && c.Severity == severityError {
+		errCount++
+	}
+
+	pos, ok := ctx.Value(util.Position).(util.FilePosition)
+	if !ok {
+		pos = util.FilePosition{Line: 1, Col: 1}
+	}
+	d := Diagnostic{
+		Severity: c.Severity,
+		Code:     c.Code,
+		File:     file,
+		Line:     pos.Line,
+		Col:      pos.Col,
+		Message:  s,
+	}
+	if suppressed {
+		d.Suppression = &justification
+	}
+	output.Diagnostics = append(output.Diagnostics, d)
+
+	if outputFormat == formatGitHub {
+		switch {
+		case suppressed:
+			log.Printf("%s: %s suppressed: %s\n", file, c.Code, justification)
+		case c.Severity == severityError:
+			util.Errf(ctx, s)
+		default:
+			util.Warnf(ctx, s)
+		}
+	}
+}
+
+// Prints free text, only with the github format
+// since other formats are machine-readable.
+func printf(format string, v ...interface{}) {
+	if outputFormat == formatGitHub {
+		fmt.Printf(format, v...)
+	}
+}
+
+// Prints the recorded output and exits,
+// with an error if any diagnostic is an error.
+func exit() {
+	// packages linted in parallel are grouped
+	sort.SliceStable(output.Diagnostics, func(i, j int) bool {
+		return output.Diagnostics[i].File < output.Diagnostics[j].File
+	})
+
+	switch outputFormat {
+	case formatJSON:
+		printJSON(output)
+	case formatSARIF:
+		printJSON(sarifLog(output.Diagnostics))
+	}
+
+	if errCount > 0 {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	util.Check(encoder.Encode(v))
+}
+
+// The subset of SARIF 2.1.0 used by the checker.
+type sarif struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+func sarifLog(diagnostics []Diagnostic) sarif {
+	rules := make([]sarifRule, 0, len(Checks))
+	for _, c := range Checks {
+		rules = append(rules, sarifRule{
+			ID:                   c.Code,
+			ShortDescription:     sarifMessage{Text: c.Description},
+			DefaultConfiguration: sarifConfiguration{Level: c.Severity},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		result := sarifResult{
+			RuleID:  d.Code,
+			Level:   d.Severity,
+			Message: sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: d.File},
+					Region:           sarifRegion{StartLine: d.Line, StartColumn: d.Col},
+				},
+			}},
+		}
+		if d.Suppression != nil {
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: *d.Suppression}}
+		}
+		results = append(results, result)
+	}
+
+	return sarif{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "cdnjs-checker",
+				InformationURI: "https://github.com/cdnjs/tools/tree/master/cmd/checker",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
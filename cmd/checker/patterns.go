@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+)
+
+// Patterns matching more files than this in a version are too broad.
+var broadPatternFiles = 1000
+
+// Directories which usually aren't meant to be published.
+var suspiciousDirs = []string{"test", "tests", "__tests__", "src", "node_modules"}
+
+// PatternResult are the files matched by a pattern of the fileMap
+// in each version listed by show-files.
+type PatternResult struct {
+	BasePath string `json:"basePath"`
+	Pattern  string `json:"pattern"`
+	// Matches is the number of files matched in each version, in the order of the versions.
+	Matches    []int    `json:"matches"`
+	Suspicious []string `json:"suspicious"`
+}
+
+// Collects the files matched by each pattern of the
+// fileMap of a package in the versions processed.
+type patternReport struct {
+	pckgPath string
+	pckg     *packages.Package
+	versions []string
+	patterns []*PatternResult
+	// the field of each pattern in the package file
+	fields []string
+}
+
+func newPatternReport(pckgPath string, pckg *packages.Package) *patternReport {
+	r := &patternReport{pckgPath: pckgPath, pckg: pckg}
+	for i, fileMap := range pckg.Autoupdate.FileMap {
+		for j, pattern := range fileMap.Files {
+			r.patterns = append(r.patterns, &PatternResult{
+				BasePath:   *fileMap.BasePath,
+				Pattern:    pattern,
+				Matches:    []int{},
+				Suspicious: []string{},
+			})
+			r.fields = append(r.fields, fmt.Sprintf("autoupdate.fileMap.%d.files.%d", i, j))
+		}
+	}
+	return r
+}
+
+// Extracts the tarball of a version and matches each pattern against its files.
+func (r *patternReport) add(ctx context.Context, version string, tarball []byte) error {
+	dir, err := ioutil.TempDir("", "patterns")
+	if err != nil {
+		return errors.Wrap(err, "failed to create version directory")
+	}
+	defer os.RemoveAll(dir)
+
+	if err := packages.ExtractTarball(bytes.NewReader(tarball), *r.pckg.Autoupdate.Source, dir); err != nil {
+		return errors.Wrap(err, "failed to extract version")
+	}
+
+	// the files published, to find the source maps of unpublished files
+	published := make(map[string]bool)
+	ops, _ := r.pckg.NpmFilesAndCollisionsFrom(dir)
+	for _, op := range ops {
+		published[op.From] = true
+	}
+
+	r.versions = append(r.versions, version)
+	for _, p := range r.patterns {
+		files, err := util.ListFilesGlob(ctx, path.Join(dir, p.BasePath), p.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "failed to match `%s`", p.Pattern)
+		}
+		p.Matches = append(p.Matches, len(files))
+
+		// files are listed once across versions
+		seen := make(map[string]bool)
+		for _, f := range p.Suspicious {
+			seen[f] = true
+		}
+		for _, f := range files {
+			if !seen[f] && isSuspicious(f, path.Join(p.BasePath, f), published) {
+				seen[f] = true
+				p.Suspicious = append(p.Suspicious, f)
+			}
+		}
+	}
+	return nil
+}
+
+// Whether a file matched by a pattern, at a path relative to the base path
+// of the pattern, is in a directory usually not published, or is the source
+// map of a file which isn't published.
+func isSuspicious(file, fromRoot string, published map[string]bool) bool {
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		for _, suspicious := range suspiciousDirs {
+			if dir == suspicious {
+				return true
+			}
+		}
+	}
+	return strings.HasSuffix(file, ".map") && !published[strings.TrimSuffix(fromRoot, ".map")]
+}
+
+// Prints the number of files matched by each pattern in each version, and
+// outputs the patterns which never match, match too many files or suspicious ones.
+func (r *patternReport) print() {
+	if len(r.versions) == 0 {
+		return
+	}
+	output.ShowFiles.Patterns = make([]PatternResult, 0, len(r.patterns))
+
+	printf("\nfiles matched per pattern (%s):\n\n| basePath | pattern | files |\n| --- | --- | --- |\n", strings.Join(r.versions, ", "))
+	for _, p := range r.patterns {
+		output.ShowFiles.Patterns = append(output.ShowFiles.Patterns, *p)
+
+		counts := make([]string, 0, len(p.Matches))
+		for _, n := range p.Matches {
+			counts = append(counts, fmt.Sprint(n))
+		}
+		basePath := "(root)"
+		if p.BasePath != "" {
+			basePath = "`" + p.BasePath + "`"
+		}
+		printf("| %s | `%s` | %s |\n", basePath, p.Pattern, strings.Join(counts, ", "))
+	}
+
+	for i, p := range r.patterns {
+		r.check(fieldContext("", r.pckgPath, r.fields[i]), p)
+	}
+}
+
+func (r *patternReport) check(ctx context.Context, p *PatternResult) {
+	var matched bool
+	for i, n := range p.Matches {
+		if n > 0 {
+			matched = true
+		}
+		if n > broadPatternFiles {
+			show(ctx, broadPattern, fmt.Sprintf("pattern `%s` matches %d files in version %s", p.Pattern, n, r.versions[i]))
+			break
+		}
+	}
+	if !matched {
+		show(ctx, deadPattern, fmt.Sprintf("pattern `%s` matches no file in the last %d version(s)", p.Pattern, len(r.versions)))
+	}
+
+	if len(p.Suspicious) > 0 {
+		examples := p.Suspicious
+		if len(examples) > 3 {
+			examples = examples[:3]
+		}
+		show(ctx, suspiciousPattern, fmt.Sprintf("pattern `%s` matches %d file(s) usually not published (tests, sources, dependencies or source maps of unpublished files), ex. `%s`", p.Pattern, len(p.Suspicious), strings.Join(examples, "`, `")))
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/cdnjs/tools/compress"
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+)
+
+// Files larger than this (90% of the limit) are flagged,
+// since they would be ignored if they grew slightly.
+var nearMaxFileSize = util.MaxFileSize / 10 * 9
+
+// PublishedFile is a file published for a version, with its sizes in bytes.
+// The compressed sizes are nil for the files which aren't compressed, and
+// for brotli without the sandbox.
+type PublishedFile struct {
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	Gzip        *int64 `json:"gzip,omitempty"`
+	Brotli      *int64 `json:"brotli,omitempty"`
+	NearMaxSize bool   `json:"nearMaxSize"`
+}
+
+// Returns the sizes of a file published from the output directory of the sandbox,
+// which contains the gzip and brotli files, or the file itself if it isn't compressed.
+func outputFileSizes(outDir, name string) (PublishedFile, error) {
+	file := path.Join(outDir, name)
+
+	gz, err := os.Open(file + ".gz")
+	if os.IsNotExist(err) {
+		info, err := os.Stat(file)
+		if err != nil {
+			return PublishedFile{}, errors.Wrap(err, "could not inspect sandbox output")
+		}
+		return newPublishedFile(name, info.Size(), nil, nil), nil
+	}
+	if err != nil {
+		return PublishedFile{}, errors.Wrap(err, "could not inspect sandbox output")
+	}
+	defer gz.Close()
+
+	info, err := gz.Stat()
+	if err != nil {
+		return PublishedFile{}, errors.Wrap(err, "could not inspect sandbox output")
+	}
+	gzipSize := info.Size()
+
+	// the uncompressed size is only known by decompressing the file
+	r, err := gzip.NewReader(gz)
+	if err != nil {
+		return PublishedFile{}, errors.Wrap(err, "could not read gzip output")
+	}
+	size, err := io.Copy(ioutil.Discard, r)
+	if err != nil {
+		return PublishedFile{}, errors.Wrap(err, "could not read gzip output")
+	}
+
+	var brotliSize *int64
+	if info, err := os.Stat(file + ".br"); err == nil {
+		s := info.Size()
+		brotliSize = &s
+	}
+	return newPublishedFile(name, size, &gzipSize, brotliSize), nil
+}
+
+// Returns the sizes of a file published from a directory, compressing
+// it with gzip like the sandbox. Fonts are published as is.
+func dirFileSizes(dir, from, name string) (PublishedFile, error) {
+	bytes, err := ioutil.ReadFile(path.Join(dir, from))
+	if err != nil {
+		return PublishedFile{}, errors.Wrap(err, "could not read file")
+	}
+	size := int64(len(bytes))
+	if path.Ext(name) == ".woff2" {
+		return newPublishedFile(name, size, nil, nil), nil
+	}
+
+	gzipSize := int64(len(compress.Gzip9Bytes(bytes)))
+	return newPublishedFile(name, size, &gzipSize, nil), nil
+}
+
+func newPublishedFile(name string, size int64, gzipSize, brotliSize *int64) PublishedFile {
+	return PublishedFile{
+		Name:        name,
+		Size:        size,
+		Gzip:        gzipSize,
+		Brotli:      brotliSize,
+		NearMaxSize: size > nearMaxFileSize,
+	}
+}
+
+// Returns the names of published files.
+func fileNames(files []PublishedFile) []string {
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Returns the total size of published files, uncompressed.
+func totalSize(files []PublishedFile) int64 {
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	return total
+}
+
+// Prints the sizes of published files as a markdown table,
+// followed by their total size.
+func printSizes(files []PublishedFile) {
+	var total, totalGzip, totalBrotli int64
+	brotliKnown := true
+	printf("\n| file | size | gzip | brotli |\n| --- | ---: | ---: | ---: |\n")
+	for _, f := range files {
+		name := "`" + f.Name + "`"
+		if f.NearMaxSize {
+			name += " :warning: close to the limit of " + formatSize(util.MaxFileSize)
+		}
+		printf("| %s | %s | %s | %s |\n", name, formatSize(f.Size), formatOptionalSize(f.Gzip), formatOptionalSize(f.Brotli))
+
+		total += f.Size
+		totalGzip += sizeOr(f.Gzip, f.Size)
+		totalBrotli += sizeOr(f.Brotli, f.Size)
+		if f.Gzip != nil && f.Brotli == nil {
+			brotliKnown = false
+		}
+	}
+
+	brotli := "-"
+	if brotliKnown {
+		brotli = formatSize(totalBrotli)
+	}
+	printf("\ntotal: %s (gzip %s, brotli %s)\n", formatSize(total), formatSize(totalGzip), brotli)
+}
+
+// Returns a compressed size, or the uncompressed
+// size if the file isn't compressed.
+func sizeOr(size *int64, uncompressed int64) int64 {
+	if size == nil {
+		return uncompressed
+	}
+	return *size
+}
+
+func formatOptionalSize(size *int64) string {
+	if size == nil {
+		return "-"
+	}
+	return formatSize(*size)
+}
+
+// Formats a size in bytes, ex. `1.5 KiB`.
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"sync"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Suppressions of the central exceptions file, by package name
+	exceptions = make(map[string][]packages.Suppression)
+
+	// Suppressions applying to each package file
+	suppressionsMu sync.Mutex
+	suppressions   = make(map[string][]packages.Suppression)
+)
+
+// Reads the central exceptions file, which maps package names
+// to the suppressions of their warnings.
+func readExceptions(file string) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return errors.Wrap(err, "failed to read exceptions file")
+	}
+	if err := json.Unmarshal(b, &exceptions); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", file)
+	}
+
+	for name, sups := range exceptions {
+		for _, s := range sups {
+			if err := validateSuppression(s); err != nil {
+				return errors.Wrapf(err, "invalid exception for `%s`", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Checks that a suppression has a justification
+// and names a warning, since errors can't be suppressed.
+func validateSuppression(s packages.Suppression) error {
+	if s.Code == nil {
+		return errors.New("code is missing")
+	}
+	if s.Justification == nil || *s.Justification == "" {
+		return errors.Errorf("justification is missing for `%s`", *s.Code)
+	}
+	c, ok := checkOf(*s.Code)
+	if !ok {
+		return errors.Errorf("unknown code `%s`", *s.Code)
+	}
+	if c.Severity != severityWarning {
+		return errors.Errorf("`%s` is an error, which can't be suppressed", *s.Code)
+	}
+	return nil
+}
+
+// Registers the suppressions of a package, from the package itself and
+// from the exceptions file. Invalid suppressions in the package, at their
+// position in src, are ci errors.
+func suppress(ctx context.Context, pckgPath string, src []byte, pckg *packages.Package) {
+	var sups []packages.Suppression
+	for i, s := range pckg.Suppressions {
+		if err := validateSuppression(s); err != nil {
+			// each diagnostic is at the position of its own suppression, if any
+			sctx := ctx
+			field := "suppressions." + strconv.Itoa(i) + ".code"
+			if pos, ok := util.JSONFieldPosition(src, field); ok {
+				sctx = context.WithValue(ctx, util.Position, pos)
+			}
+			show(sctx, invalidSuppression, err.Error())
+			continue
+		}
+		sups = append(sups, s)
+	}
+	suppressionsMu.Lock()
+	defer suppressionsMu.Unlock()
+	suppressions[pckgPath] = append(sups, exceptions[*pckg.Name]...)
+}
+
+// Returns the justification of the suppression of a check
+// for a package file, if it is suppressed.
+func suppressionOf(pckgPath string, c Check) (string, bool) {
+	suppressionsMu.Lock()
+	defer suppressionsMu.Unlock()
+
+	for _, s := range suppressions[pckgPath] {
+		if *s.Code == c.Code {
+			return *s.Justification, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cdnjs/tools/packages"
+)
+
+func main() {
+	flag.Parse()
+
+	switch subcommand := flag.Arg(0); subcommand {
+	case "human":
+		{
+			fmt.Println(packages.HumanReadableSchemaString)
+		}
+	case "non-human":
+		{
+			fmt.Println(packages.NonHumanReadableSchemaString)
+		}
+	case "check":
+		{
+			human, nonHuman := "schema_human.json", "schema_non_human.json"
+			if flag.NArg() == 3 {
+				human, nonHuman = flag.Arg(1), flag.Arg(2)
+			}
+
+			ok := checkSchema(human, packages.HumanReadableSchemaString)
+			ok = checkSchema(nonHuman, packages.NonHumanReadableSchemaString) && ok
+			if !ok {
+				os.Exit(1)
+			}
+		}
+	default:
+		panic(fmt.Sprintf("unknown subcommand: `%s`", subcommand))
+	}
+}
+
+// Checks that a committed schema file is up to date with the schema.
+func checkSchema(file string, schema string) bool {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatalf("failed to read schema: %s\n", err)
+	}
+
+	if !equalSchema(bytes, schema) {
+		fmt.Printf("%s is out of date, run `make schema`\n", file)
+		return false
+	}
+	return true
+}
+
+func equalSchema(file []byte, schema string) bool {
+	return bytes.Equal(bytes.TrimRight(file, "\n"), []byte(strings.TrimRight(schema, "\n")))
+}
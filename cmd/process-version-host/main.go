@@ -21,7 +21,9 @@ import (
 	"github.com/cdnjs/tools/audit"
 	"github.com/cdnjs/tools/metrics"
 	"github.com/cdnjs/tools/sandbox"
+	"github.com/cdnjs/tools/secrets"
 	"github.com/cdnjs/tools/sentry"
+	"github.com/cdnjs/tools/version"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/pkg/errors"
@@ -65,6 +67,7 @@ type Message struct {
 	Pkg               string           `json:"package"`
 	Version           string           `json:"version"`
 	Config            *json.RawMessage `json:"config"`
+	Integrity         string           `json:"integrity"`
 }
 
 func consume(client *pubsub.Client, sub *pubsub.Subscription) error {
@@ -99,7 +102,7 @@ func processMessage(ctx context.Context, data []byte) error {
 	if err := writeConfig(inDir, message.Config); err != nil {
 		return errors.Wrap(err, "failed to write configuration")
 	}
-	if err := download(inDir, message.Tar); err != nil {
+	if err := download(inDir, message.Tar, message.Integrity); err != nil {
 		return errors.Wrapf(err, "failed to download: %s", message.Tar)
 	}
 
@@ -113,6 +116,11 @@ func processMessage(ctx context.Context, data []byte) error {
 	if err := audit.ProcessedVersion(ctx, message.Pkg, message.Version, logs); err != nil {
 		return errors.Wrap(err, "could not post audit")
 	}
+	if findings := secrets.ParseFindings(logs); len(findings) > 0 {
+		if err := audit.FoundSensitiveFiles(ctx, message.Pkg, message.Version, findings); err != nil {
+			return errors.Wrap(err, "could not post audit")
+		}
+	}
 	if err := metrics.NewUpdateProccessed(); err != nil {
 		return errors.Wrap(err, "could not report metrics")
 	}
@@ -167,21 +175,33 @@ func writeConfig(dstDir string, config *json.RawMessage) error {
 	return nil
 }
 
-func download(dstDir string, url string) error {
+// Downloads the tarball of a version, verifying it against the
+// integrity recorded when it was detected, if any.
+func download(dstDir string, url string, integrity string) error {
 	resp, err := http.Get(url)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	// an error page isn't reported as a modified tarball
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("could not download %s: %s", url, resp.Status)
+	}
 
-	dst, err := os.Create(path.Join(dstDir, "new-version.tgz"))
-	if err != nil {
-		return errors.Wrap(err, "could not write tmp file")
+	var buff bytes.Buffer
+	if _, err := buff.ReadFrom(resp.Body); err != nil {
+		return errors.Wrap(err, "could not read tarball")
+	}
+	if integrity != "" {
+		if err := version.VerifyIntegrity(buff.Bytes(), integrity); err != nil {
+			return errors.Wrap(err, "tarball was modified since it was detected")
+		}
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, resp.Body)
-	return err
+	if err := ioutil.WriteFile(path.Join(dstDir, "new-version.tgz"), buff.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "could not write tmp file")
+	}
+	return nil
 }
 
 func compress(src string, buf io.Writer) error {
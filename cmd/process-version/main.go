@@ -1,8 +1,6 @@
 package main
 
 import (
-	"archive/tar"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -18,6 +16,7 @@ import (
 
 	"github.com/cdnjs/tools/compress"
 	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/secrets"
 	"github.com/cdnjs/tools/sri"
 
 	"github.com/pkg/errors"
@@ -126,21 +125,6 @@ func (j optimizeJob) emit(name string) {
 	j.emitFromWorkspace(src)
 }
 
-func removePackageDir(path string) string {
-	if len(path) < 8 {
-		return path
-	}
-	if path[0:8] == "package/" {
-		return path[8:]
-	}
-	return path
-}
-
-func removeFirstDir(path string) string {
-	parts := strings.Split(path, "/")
-	return strings.Replace(path, parts[0]+"/", "", 1)
-}
-
 func readConfig() (*packages.Package, error) {
 	file := path.Join(INPUT, "config.json")
 	data, err := ioutil.ReadFile(file)
@@ -159,56 +143,9 @@ func extractInput(source string) error {
 	if err != nil {
 		return errors.Wrap(err, "could not open input")
 	}
+	defer gzipStream.Close()
 
-	uncompressedStream, err := gzip.NewReader(gzipStream)
-	if err != nil {
-		return errors.Wrap(err, "could not create reader")
-	}
-
-	tarReader := tar.NewReader(uncompressedStream)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.Fatalf("ExtractTarGz: Next() failed: %s", err.Error())
-		}
-
-		target := header.Name
-		if source == "npm" {
-			// remove package folder
-			target = removePackageDir(header.Name)
-		}
-		if source == "git" {
-			// remove package folder
-			target = removeFirstDir(header.Name)
-		}
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			// ignore dirs
-		case tar.TypeReg:
-			if err := os.MkdirAll(path.Join(WORKSPACE, filepath.Dir(target)), 0755); err != nil {
-				return errors.Wrap(err, "ExtractTarGz: Mkdir() failed")
-			}
-			outFile, err := os.Create(path.Join(WORKSPACE, target))
-			if err != nil {
-				return errors.Wrap(err, "ExtractTarGz: Create() failed")
-			}
-			defer outFile.Close()
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				return errors.Wrap(err, "ExtractTarGz: Copy() failed")
-			}
-		default:
-			log.Printf(
-				"ExtractTarGz: uknown type: %x in %s\n",
-				header.Typeflag,
-				header.Name)
-		}
-	}
-	return nil
+	return packages.ExtractTarball(gzipStream, source, WORKSPACE)
 }
 
 func optimizeWorker(wg *sync.WaitGroup, jobs <-chan optimizeJob) {
@@ -255,7 +192,10 @@ func optimizePackage(ctx context.Context, config *packages.Package) error {
 		config.Optimization.Png(),
 		config.Optimization.Jpg())
 
-	files := config.NpmFilesFrom(WORKSPACE)
+	files, err := withoutSensitiveFiles(config.NpmFilesFrom(WORKSPACE))
+	if err != nil {
+		return errors.Wrap(err, "failed to scan files")
+	}
 	cpuCount := runtime.NumCPU()
 	jobs := make(chan optimizeJob, cpuCount)
 
@@ -280,6 +220,27 @@ func optimizePackage(ctx context.Context, config *packages.Package) error {
 	return nil
 }
 
+// Removes the files which look sensitive, since they would be public
+// once published, logging them. Files which may contain a secret, but
+// not a whole private key, are published and logged too.
+func withoutSensitiveFiles(files []packages.NpmFileMoveOp) ([]packages.NpmFileMoveOp, error) {
+	out := make([]packages.NpmFileMoveOp, 0, len(files))
+	for _, file := range files {
+		finding, err := secrets.ScanFile(path.Join(WORKSPACE, file.From), file.From)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan file")
+		}
+		if finding != nil {
+			log.Println(finding)
+			if finding.Blocked {
+				continue
+			}
+		}
+		out = append(out, file)
+	}
+	return out, nil
+}
+
 func copyFile(src, dest string) error {
 	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
 		return errors.Wrap(err, "could not create dir")
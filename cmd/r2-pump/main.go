@@ -18,6 +18,7 @@ import (
 
 	"github.com/cdnjs/tools/audit"
 	"github.com/cdnjs/tools/gcp"
+	"github.com/cdnjs/tools/kv"
 	"github.com/cdnjs/tools/metrics"
 	"github.com/cdnjs/tools/packages"
 	"github.com/cdnjs/tools/sentry"
@@ -133,7 +134,7 @@ func invoke(ctx context.Context, e gcp.GCSEvent) error {
 	onFile := func(name string, r io.Reader) error {
 		// remove leading slash
 		name = name[1:]
-		key := fmt.Sprintf("%s/%s/%s", pkgName, version, name)
+		key := kv.FileKey(pkgName, version, name)
 
 		content, err := ioutil.ReadAll(r)
 		if err != nil {
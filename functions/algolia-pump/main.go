@@ -22,6 +22,7 @@ import (
 	"github.com/cdnjs/tools/metrics"
 	"github.com/cdnjs/tools/packages"
 	"github.com/cdnjs/tools/sentry"
+	"github.com/cdnjs/tools/version"
 )
 
 var (
@@ -114,7 +115,7 @@ func Invoke(ctx context.Context, e gcp.GCSEvent) error {
 	}
 
 	// Update package's current version and fix filename if needed
-	pkg.Version = packages.GetLatestStableVersion(versions)
+	pkg.Version = version.GetLatestStableVersion(versions)
 	if err := packages.UpdateFilenameIfMissing(ctx, pkg, files); err != nil {
 		return errors.Wrap(err, "failed to fix missing filename")
 	}
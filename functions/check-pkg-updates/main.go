@@ -1,6 +1,7 @@
 package check_pkg_updates
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
@@ -54,7 +55,7 @@ func Invoke(w http.ResponseWriter, r *http.Request) {
 		panic("PKG_AUTOUPDATE_SOURCE should be present")
 	}
 
-	list, err := packages.FetchPackages()
+	list, err := packages.DefaultSource().Packages(context.Background())
 	if err != nil {
 		http.Error(w, "failed to fetch packages", 500)
 		fmt.Println(err)
@@ -20,12 +20,27 @@ import (
 )
 
 func updatePackage(ctx context.Context, pkg *packages.Package, src string) error {
+	// the name is used as is in the objects and keys of the versions,
+	// not the npm target which may be scoped, ex. `@scope/name`
+	if !packages.IsLibraryName(*pkg.Name) {
+		return errors.Errorf("invalid library name, ex. `%s`", packages.SuggestedName(pkg))
+	}
+
+	// a package with an invalid range is skipped, since none of its versions match
+	if r := pkg.Autoupdate.VersionRange; r != nil {
+		if _, err := version.ParseRange(*r); err != nil {
+			return errors.Wrap(err, "invalid version range")
+		}
+	}
+
 	existingVersionSet, err := getExistingVersions(pkg)
 	if err != nil {
 		return errors.Wrap(err, "could not detect existing versions")
 	}
 	log.Printf("%s: existing versions: %s\n", *pkg.Name, strings.Join(existingVersionSet, ","))
 
+	// versions excluded by the version range or the
+	// prerelease policy of the package are not listed
 	var versions []version.Version
 
 	switch src {
@@ -35,7 +50,20 @@ func updatePackage(ctx context.Context, pkg *packages.Package, src string) error
 			return errors.Wrap(err, "failed to get git versions")
 		}
 	case "npm":
-		versions, _ = npm.GetVersions(ctx, pkg.Autoupdate)
+		client := npm.DefaultClient()
+		versions, _, err = client.GetVersions(ctx, pkg.Autoupdate)
+		if err != nil {
+			return errors.Wrap(err, "failed to get npm versions")
+		}
+		// the publication times are only fetched when there are new
+		// versions, since they are only part of the full metadata
+		if len(version.VersionDiff(versions, existingVersionSet)) == 0 {
+			log.Printf("%s: no new version\n", *pkg.Name)
+			return nil
+		}
+		if err := client.DateVersions(ctx, *pkg.Autoupdate.Target, versions); err != nil {
+			return errors.Wrap(err, "failed to date npm versions")
+		}
 	default:
 		panic("unreachable")
 	}
@@ -90,21 +118,38 @@ func updatePackage(ctx context.Context, pkg *packages.Package, src string) error
 	return nil
 }
 
+// DoUpdate sends the first of the versions which can be verified to be
+// processed. Versions which don't match their integrity are skipped and
+// audited, so that they don't block the newer ones on every run.
 func DoUpdate(ctx context.Context, pkg *packages.Package, versions []version.Version) error {
-	if len(versions) == 0 {
-		return nil
+	for _, v := range versions {
+		err := updateVersion(ctx, pkg, v)
+		if verr, ok := errors.Cause(err).(version.VerifyError); ok {
+			log.Printf("%s: version %s skipped: %s\n", *pkg.Name, v.Version, verr)
+			if err := audit.UnverifiedVersion(ctx, *pkg.Name, v.Version, verr.Error()); err != nil {
+				return errors.Wrap(err, "could not audit")
+			}
+			continue
+		}
+		// only update one versions at a time to reduce race conditions
+		return err
 	}
-	// only update one versions at a time to reduce race conditions
-	v := versions[0]
+	return nil
+}
 
+func updateVersion(ctx context.Context, pkg *packages.Package, v version.Version) error {
 	log.Printf("%s: new version detected: %s\n", *pkg.Name, v.Version)
-	tarball := version.DownloadTar(ctx, v)
+	// a tarball which doesn't match its integrity isn't published
+	tarball, err := version.DownloadTar(ctx, v)
+	if err != nil {
+		return errors.Wrap(err, "could not download version")
+	}
 	filename := fmt.Sprintf("%s-%s.tgz", *pkg.Name, v.Version)
 	if err := gcp.AddIncomingFile(filename, tarball, pkg, v); err != nil {
 		return errors.Wrap(err, "could not store in GCS: %s")
 	}
 
-	if err := audit.NewVersionDetected(ctx, *pkg.Name, v.Version); err != nil {
+	if err := audit.NewVersionDetected(ctx, *pkg.Name, v.Version, v.Digest()); err != nil {
 		return errors.Wrap(err, "could not audit")
 	}
 	if err := metrics.NewUpdateDetected(); err != nil {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 
 	"github.com/cdnjs/tools/audit"
@@ -28,63 +27,96 @@ func Invoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	list, err := packages.FetchPackages()
-	if err != nil {
+	ctx := context.Background()
+	source := packages.DefaultSource()
+	if _, err := source.Packages(ctx); err != nil {
 		http.Error(w, "failed to fetch packages", 500)
 		fmt.Println(err)
 		return
 	}
-	ctx := context.Background()
 
-	for _, pkg := range list {
-		if *pkg.Name == d.Pkg {
-			src := *pkg.Autoupdate.Source
-			var versions []version.Version
-			switch src {
-			case "git":
-				versions, err = git.GetVersionsWithLimit(ctx, pkg.Autoupdate, 100)
-				if err != nil {
-					http.Error(w, "failed to fetch versions", 500)
-					fmt.Println(err)
-					return
-				}
-			case "npm":
-				versions, _ = npm.GetVersions(ctx, pkg.Autoupdate)
-			default:
-				panic("unreachable")
-			}
+	pkg, err := source.Package(ctx, d.Pkg)
+	if err != nil {
+		http.Error(w, "package or version not found", 404)
+		fmt.Println(err)
+		return
+	}
 
-			var targetVersion *version.Version
-			for _, version := range versions {
-				if version.Version == d.Version {
-					targetVersion = &version
-					break
-				}
-			}
+	if !packages.IsLibraryName(*pkg.Name) {
+		msg := fmt.Sprintf("invalid library name `%s`, ex. `%s`", *pkg.Name, packages.SuggestedName(pkg))
+		http.Error(w, msg, 400)
+		return
+	}
 
-			if targetVersion == nil {
-				var versionNames []string
-				for _, version := range versions {
-					versionNames = append(versionNames, version.Version)
-				}
-				msg := fmt.Sprintf("target version `%s` not found: %v", d.Version, versionNames)
-				http.Error(w, msg, 500)
-				return
-			}
-			tarball := version.DownloadTar(ctx, *targetVersion)
-			filename := fmt.Sprintf("%s-%s.tgz", *pkg.Name, targetVersion.Version)
-			if err := gcp.AddIncomingFile(filename, tarball, pkg, *targetVersion); err != nil {
-				log.Fatalf("could not store in GCS: %s", err)
-			}
-			if err := audit.NewVersionDetected(ctx, *pkg.Name, targetVersion.Version); err != nil {
-				log.Fatalf("could not audit: %s", err)
-			}
+	if r := pkg.Autoupdate.VersionRange; r != nil {
+		rng, err := version.ParseRange(*r)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		if !version.InRange(pkg.Autoupdate, rng, d.Version) {
+			msg := fmt.Sprintf("target version `%s` does not satisfy the version range `%s`", d.Version, *r)
+			http.Error(w, msg, 400)
+			return
+		}
+	}
 
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(fmt.Sprintf("OK, file: https://storage.googleapis.com/cdnjs-incoming-prod/%s", filename)))
+	src := *pkg.Autoupdate.Source
+	var versions []version.Version
+	switch src {
+	case "git":
+		versions, err = git.GetVersionsWithLimit(ctx, pkg.Autoupdate, 100)
+		if err != nil {
+			http.Error(w, "failed to fetch versions", 500)
+			fmt.Println(err)
+			return
+		}
+	case "npm":
+		versions, _, err = npm.DefaultClient().GetVersions(ctx, pkg.Autoupdate)
+		if err != nil {
+			http.Error(w, "failed to fetch versions", 500)
+			fmt.Println(err)
 			return
 		}
+	default:
+		panic("unreachable")
+	}
+
+	var targetVersion *version.Version
+	for _, version := range versions {
+		if version.Version == d.Version {
+			targetVersion = &version
+			break
+		}
+	}
+
+	if targetVersion == nil {
+		var versionNames []string
+		for _, version := range versions {
+			versionNames = append(versionNames, version.Version)
+		}
+		msg := fmt.Sprintf("target version `%s` not found: %v", d.Version, versionNames)
+		http.Error(w, msg, 500)
+		return
+	}
+	tarball, err := version.DownloadTar(ctx, *targetVersion)
+	if err != nil {
+		http.Error(w, "failed to download version", 500)
+		fmt.Println(err)
+		return
+	}
+	filename := fmt.Sprintf("%s-%s.tgz", *pkg.Name, targetVersion.Version)
+	if err := gcp.AddIncomingFile(filename, tarball, pkg, *targetVersion); err != nil {
+		http.Error(w, "failed to store version", 500)
+		fmt.Println(err)
+		return
+	}
+	if err := audit.NewVersionDetected(ctx, *pkg.Name, targetVersion.Version, targetVersion.Digest()); err != nil {
+		http.Error(w, "failed to audit version", 500)
+		fmt.Println(err)
+		return
 	}
 
-	http.Error(w, "package or version not found", 404)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf("OK, file: https://storage.googleapis.com/cdnjs-incoming-prod/%s", filename)))
 }
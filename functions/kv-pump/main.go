@@ -20,6 +20,7 @@ import (
 	"github.com/cdnjs/tools/metrics"
 	"github.com/cdnjs/tools/packages"
 	"github.com/cdnjs/tools/sentry"
+	"github.com/cdnjs/tools/version"
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 	"github.com/pkg/errors"
@@ -64,7 +65,7 @@ func Invoke(ctx context.Context, e gcp.GCSEvent) error {
 		ext := filepath.Ext(name)
 		// remove leading slash
 		name = name[1:]
-		key := fmt.Sprintf("%s/%s/%s", pkgName, version, name)
+		key := kv.FileKey(pkgName, version, name)
 
 		content, err := ioutil.ReadAll(r)
 		if err != nil {
@@ -194,7 +195,7 @@ func updatePackage(ctx context.Context, cfapi *cloudflare.API, pkg *packages.Pac
 		log.Println("updatePackage: update contains no files, ignoring")
 	}
 
-	pkg.Version = packages.GetLatestStableVersion(versions)
+	pkg.Version = version.GetLatestStableVersion(versions)
 	log.Println("updated package", pkg)
 
 	if err := packages.UpdateFilenameIfMissing(ctx, pkg, files); err != nil {
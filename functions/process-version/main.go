@@ -37,10 +37,12 @@ func Invoke(ctx context.Context, e gcp.GCSEvent) error {
 	pkg := e.Metadata["package"].(string)
 	version := e.Metadata["version"].(string)
 	config := e.Metadata["config"].(string)
+	// the digest the tarball was verified against when downloaded, if any
+	integrity, _ := e.Metadata["integrity"].(string)
 
 	url := fmt.Sprintf("https://storage.googleapis.com/%s/%s", e.Bucket, e.Name)
 
-	if err := publish(url, pkg, version, config); err != nil {
+	if err := publish(url, pkg, version, config, integrity); err != nil {
 		return fmt.Errorf("failed to publish: %v", err)
 	}
 	return nil
@@ -52,9 +54,10 @@ type Message struct {
 	Pkg               string           `json:"package"`
 	Version           string           `json:"version"`
 	Config            packages.Package `json:"config"`
+	Integrity         string           `json:"integrity,omitempty"`
 }
 
-func publish(tar, pkg, version, configStr string) error {
+func publish(tar, pkg, version, configStr, integrity string) error {
 	ctx := context.Background()
 	client, err := pubsub.NewClient(ctx, PROJECT)
 	if err != nil {
@@ -79,6 +82,7 @@ func publish(tar, pkg, version, configStr string) error {
 		Pkg:               pkg,
 		Version:           version,
 		Config:            config,
+		Integrity:         integrity,
 	}
 	bytes, err := json.Marshal(msg)
 	if err != nil {
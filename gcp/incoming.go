@@ -46,13 +46,19 @@ func AddIncomingFile(fileName string, buff bytes.Buffer, pckg *packages.Package,
 		return fmt.Errorf("failed to marshal filemap: %v", err)
 	}
 
+	metadata := map[string]string{
+		"version": v.Version,
+		"package": *pckg.Name,
+		"config":  string(configBytes),
+	}
+	// the digest the tarball was verified against, if any
+	if digest := v.Digest(); digest != "" {
+		metadata["integrity"] = digest
+	}
+
 	// update the metadata once the object is written
 	_, err = obj.Update(ctx, storage.ObjectAttrsToUpdate{
-		Metadata: map[string]string{
-			"version": v.Version,
-			"package": *pckg.Name,
-			"config":  string(configBytes),
-		},
+		Metadata: metadata,
 	})
 	if err != nil {
 		return errors.Wrap(err, "could not update metadata")
@@ -41,20 +41,55 @@ func getRepo(gitURL string) string {
 	return re.ReplaceAllString(gitURL, "$1")
 }
 
-// GetGitHubStars uses the GitHub API to get the star count for a
-// particular GitHub repository.
-func GetGitHubStars(gitURL string) Stars {
+// Gets a GitHub repository from the GitHub API, authenticated with GH_TOKEN
+// if set, into v. A repository which doesn't exist leaves v unchanged. The
+// response is shared through util.CachedGet, which reports rate limits.
+func getGitHubRepo(gitURL string, v interface{}) error {
+	header := make(http.Header)
+	if GH_TOKEN != "" {
+		header.Set("Authorization", "bearer "+GH_TOKEN)
+	}
 	gitHubRepository := getRepo(gitURL)
-	resp, err := http.Get(util.GetProtocol() + "://api.github.com/repos/" + gitHubRepository)
-	util.Check(err)
-
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	util.Check(err)
+	resp, err := util.CachedGet(util.GetProtocol()+"://api.github.com/repos/"+gitHubRepository, header)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, v); err != nil {
+		return errors.Wrapf(err, "could not parse repository %s", gitHubRepository)
+	}
+	return nil
+}
 
+// GetGitHubStars uses the GitHub API to get the star count for a
+// particular GitHub repository, authenticated with GH_TOKEN if set.
+// The response is shared through util.CachedGet.
+func GetGitHubStars(gitURL string) (Stars, error) {
 	var stars Stars
-	util.Check(json.Unmarshal(body, &stars))
-	return stars
+	err := getGitHubRepo(gitURL, &stars)
+	return stars, err
+}
+
+// GetGitHubLicense uses the GitHub API to get the SPDX identifier of the
+// license detected in a particular GitHub repository, or an empty string
+// if GitHub doesn't recognize it, authenticated with GH_TOKEN if set.
+// The response is shared through util.CachedGet.
+func GetGitHubLicense(gitURL string) (string, error) {
+	var repo struct {
+		License *struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+
+	if err := getGitHubRepo(gitURL, &repo); err != nil {
+		return "", err
+	}
+	if repo.License == nil || repo.License.SPDXID == "NOASSERTION" {
+		return "", nil
+	}
+	return repo.License.SPDXID, nil
 }
 
 // GetClient gets a GitHub client to interact with its API.
@@ -209,5 +244,5 @@ query {
 		}
 	}
 
-	return versions, nil
+	return version.FilterPrereleases(config, versions), nil
 }
@@ -0,0 +1,280 @@
+package glob
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Placeholders used to protect escaped characters during brace expansion.
+const (
+	escSlash  = "\x00SLASH\x00"
+	escOpen   = "\x00OPEN\x00"
+	escClose  = "\x00CLOSE\x00"
+	escComma  = "\x00COMMA\x00"
+	escPeriod = "\x00PERIOD\x00"
+)
+
+var (
+	hasBraces       = regexp.MustCompile(`\{.*\}`)
+	numericSequence = regexp.MustCompile(`^-?\d+\.\.-?\d+(?:\.\.-?\d+)?$`)
+	alphaSequence   = regexp.MustCompile(`^[a-zA-Z]\.\.[a-zA-Z](?:\.\.-?\d+)?$`)
+	paddedNumber    = regexp.MustCompile(`^-?0\d`)
+	commaThenClose  = regexp.MustCompile(`,.*\}`)
+)
+
+// expandBraces performs bash-style brace expansion on a pattern
+// in the same way as the brace-expansion module used by node-glob,
+// for example `dist/*.{js,css}` -> [`dist/*.js`, `dist/*.css`].
+func expandBraces(pattern string) []string {
+	if !hasBraces.MatchString(pattern) {
+		return []string{pattern}
+	}
+
+	// bash preserves a leading {} at the top level
+	if strings.HasPrefix(pattern, "{}") {
+		pattern = `\{\}` + pattern[2:]
+	}
+
+	expanded := expand(escapeBraces(pattern), true)
+	for i, e := range expanded {
+		expanded[i] = unescapeBraces(e)
+	}
+	return expanded
+}
+
+func escapeBraces(s string) string {
+	s = strings.ReplaceAll(s, `\\`, escSlash)
+	s = strings.ReplaceAll(s, `\{`, escOpen)
+	s = strings.ReplaceAll(s, `\}`, escClose)
+	s = strings.ReplaceAll(s, `\,`, escComma)
+	return strings.ReplaceAll(s, `\.`, escPeriod)
+}
+
+func unescapeBraces(s string) string {
+	s = strings.ReplaceAll(s, escSlash, `\`)
+	s = strings.ReplaceAll(s, escOpen, "{")
+	s = strings.ReplaceAll(s, escClose, "}")
+	s = strings.ReplaceAll(s, escComma, ",")
+	return strings.ReplaceAll(s, escPeriod, ".")
+}
+
+// balance is the first balanced brace set in a string.
+type balance struct {
+	pre, body, post string
+}
+
+// Finds the first balanced `{...}` in a string.
+func balanced(s string) (balance, bool) {
+	start, end, ok := balancedRange(s)
+	if !ok {
+		return balance{}, false
+	}
+	return balance{
+		pre:  s[:start],
+		body: s[start+1 : end],
+		post: s[end+1:],
+	}, true
+}
+
+func balancedRange(s string) (int, int, bool) {
+	ai := indexFrom(s, "{", 0)
+	bi := indexFrom(s, "}", ai+1)
+	if ai < 0 || bi <= 0 {
+		return 0, 0, false
+	}
+
+	var begs []int
+	left, right := len(s), 0
+	i := ai
+	for i >= 0 {
+		if i == ai {
+			begs = append(begs, i)
+			ai = indexFrom(s, "{", i+1)
+		} else if len(begs) == 1 {
+			return begs[0], bi, true
+		} else {
+			beg := begs[len(begs)-1]
+			begs = begs[:len(begs)-1]
+			if beg < left {
+				left, right = beg, bi
+			}
+			bi = indexFrom(s, "}", i+1)
+		}
+
+		if ai < bi && ai >= 0 {
+			i = ai
+		} else {
+			i = bi
+		}
+	}
+
+	if len(begs) > 0 {
+		return left, right, true
+	}
+	return 0, 0, false
+}
+
+func indexFrom(s, substr string, from int) int {
+	if from < 0 {
+		from = 0
+	}
+	if from > len(s) {
+		return -1
+	}
+	i := strings.Index(s[from:], substr)
+	if i < 0 {
+		return -1
+	}
+	return from + i
+}
+
+// Splits the body of a brace set on commas, keeping nested
+// brace sets such as {a,{b,c},d} together.
+func parseCommaParts(s string) []string {
+	if s == "" {
+		return []string{""}
+	}
+
+	m, ok := balanced(s)
+	if !ok {
+		return strings.Split(s, ",")
+	}
+
+	parts := strings.Split(m.pre, ",")
+	parts[len(parts)-1] += "{" + m.body + "}"
+	postParts := parseCommaParts(m.post)
+	if m.post != "" {
+		parts[len(parts)-1] += postParts[0]
+		parts = append(parts, postParts[1:]...)
+	}
+	return parts
+}
+
+func expand(s string, isTop bool) []string {
+	m, ok := balanced(s)
+	if !ok || strings.HasSuffix(m.pre, "$") {
+		return []string{s}
+	}
+
+	isNumericSequence := numericSequence.MatchString(m.body)
+	isAlphaSequence := alphaSequence.MatchString(m.body)
+	isSequence := isNumericSequence || isAlphaSequence
+	isOptions := strings.Contains(m.body, ",")
+	if !isSequence && !isOptions {
+		// {a},b}
+		if commaThenClose.MatchString(m.post) {
+			return expand(m.pre+"{"+m.body+escClose+m.post, false)
+		}
+		return []string{s}
+	}
+
+	var n []string
+	if isSequence {
+		n = strings.Split(m.body, "..")
+	} else {
+		n = parseCommaParts(m.body)
+		if len(n) == 1 {
+			// x{{a,b}}y -> x{a}y x{b}y
+			n = expand(n[0], false)
+			for i := range n {
+				n[i] = "{" + n[i] + "}"
+			}
+			if len(n) == 1 {
+				var out []string
+				for _, p := range expandPost(m.post) {
+					out = append(out, m.pre+n[0]+p)
+				}
+				return out
+			}
+		}
+	}
+
+	var values []string
+	if isSequence {
+		values = expandSequence(n, isAlphaSequence)
+	} else {
+		for _, el := range n {
+			values = append(values, expand(el, false)...)
+		}
+	}
+
+	post := expandPost(m.post)
+	var expansions []string
+	for _, v := range values {
+		for _, p := range post {
+			expansion := m.pre + v + p
+			if !isTop || isSequence || expansion != "" {
+				expansions = append(expansions, expansion)
+			}
+		}
+	}
+	return expansions
+}
+
+func expandPost(post string) []string {
+	if post == "" {
+		return []string{""}
+	}
+	return expand(post, false)
+}
+
+// Expands a {x..y} or {x..y..incr} sequence.
+func expandSequence(n []string, isAlpha bool) []string {
+	x, y := sequenceValue(n[0]), sequenceValue(n[1])
+	width := len(n[0])
+	if len(n[1]) > width {
+		width = len(n[1])
+	}
+
+	incr := 1
+	if len(n) == 3 {
+		if incr = sequenceValue(n[2]); incr < 0 {
+			incr = -incr
+		}
+		if incr == 0 {
+			incr = 1
+		}
+	}
+	reverse := y < x
+	if reverse {
+		incr = -incr
+	}
+
+	pad := false
+	for _, el := range n {
+		if paddedNumber.MatchString(el) {
+			pad = true
+		}
+	}
+
+	var out []string
+	for i := x; (!reverse && i <= y) || (reverse && i >= y); i += incr {
+		var c string
+		if isAlpha {
+			if c = string(rune(i)); c == `\` {
+				c = ""
+			}
+		} else {
+			c = strconv.Itoa(i)
+			if need := width - len(c); pad && need > 0 {
+				zeros := strings.Repeat("0", need)
+				if i < 0 {
+					c = "-" + zeros + c[1:]
+				} else {
+					c = zeros + c
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Numeric value of a sequence bound, or its character code for letters.
+func sequenceValue(s string) int {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	return int([]rune(s)[0])
+}
@@ -0,0 +1,190 @@
+// Package glob lists files matching glob patterns with the same
+// semantics as the node glob tool (https://github.com/cdnjs/glob)
+// historically used to evaluate the fileMap of packages.
+//
+// It implements the subset of node-glob v7 and minimatch used by
+// cdnjs: brace expansion, extglobs, character classes, globstars
+// which do not follow symlinks, and dotfiles only matching when the
+// pattern segment explicitly starts with a dot. Only files are
+// listed, never directories. Absolute patterns never match.
+package glob
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// List returns the paths relative to base of the files matching
+// pattern, sorted and without duplicates.
+func List(base, pattern string) []string {
+	g := &globber{
+		base:    base,
+		dirs:    make(map[string][]string),
+		matches: make(map[string]bool),
+	}
+
+	for _, parts := range compile(pattern) {
+		if len(parts) > 1 && parts[0].isLiteral() && parts[0].literal == "" {
+			// absolute pattern
+			continue
+		}
+		g.process(parts, false)
+	}
+
+	list := make([]string, 0, len(g.matches))
+	for m := range g.matches {
+		list = append(list, m)
+	}
+	sort.Strings(list)
+	return list
+}
+
+type globber struct {
+	base    string
+	dirs    map[string][]string
+	matches map[string]bool
+}
+
+func (g *globber) abs(p string) string {
+	return filepath.Join(g.base, filepath.FromSlash(p))
+}
+
+func joinLiterals(parts []part) string {
+	names := make([]string, len(parts))
+	for i, p := range parts {
+		names[i] = p.literal
+	}
+	return strings.Join(names, "/")
+}
+
+func (g *globber) process(pattern []part, inGlobStar bool) {
+	n := 0
+	for n < len(pattern) && pattern[n].isLiteral() {
+		n++
+	}
+	if n == len(pattern) {
+		g.processSimple(joinLiterals(pattern))
+		return
+	}
+
+	var prefix []part
+	read := "."
+	if n > 0 {
+		prefix = []part{{literal: joinLiterals(pattern[:n])}}
+		read = prefix[0].literal
+	}
+
+	remain := pattern[n:]
+	if remain[0].globstar {
+		g.processGlobStar(prefix, g.abs(read), remain, inGlobStar)
+	} else {
+		g.processReaddir(prefix, g.abs(read), remain, inGlobStar)
+	}
+}
+
+// Emits p if it exists and is not a directory.
+func (g *globber) processSimple(p string) {
+	if strings.HasSuffix(p, "/") {
+		// only matches directories
+		return
+	}
+
+	abs := g.abs(p)
+	info, err := os.Stat(abs)
+	if err != nil {
+		// broken symlinks are reported as files
+		if info, err = os.Lstat(abs); err != nil {
+			return
+		}
+	}
+	if info.IsDir() {
+		return
+	}
+	g.matches[p] = true
+}
+
+func (g *globber) processReaddir(prefix []part, abs string, remain []part, inGlobStar bool) {
+	entries, ok := g.readdir(abs)
+	if !ok {
+		return
+	}
+
+	seg := remain[0].segment
+	dotOk := strings.HasPrefix(seg.raw, ".")
+	for _, e := range entries {
+		if strings.HasPrefix(e, ".") && !dotOk {
+			continue
+		}
+		if !seg.match(e) {
+			continue
+		}
+
+		next := append(append([]part{}, prefix...), part{literal: e})
+		g.process(append(next, remain[1:]...), inGlobStar)
+	}
+}
+
+func (g *globber) processGlobStar(prefix []part, abs string, remain []part, inGlobStar bool) {
+	entries, ok := g.readdir(abs)
+	if !ok {
+		return
+	}
+
+	// the globstar matching zero directories
+	noGlobStar := append(append([]part{}, prefix...), remain[1:]...)
+	g.process(noGlobStar, false)
+
+	// a globstar may end on a symlinked directory but never walks
+	// through one
+	if inGlobStar && isSymlink(abs) {
+		return
+	}
+
+	for _, e := range entries {
+		if strings.HasPrefix(e, ".") {
+			continue
+		}
+
+		instead := append(append([]part{}, prefix...), part{literal: e})
+		instead = append(instead, remain[1:]...)
+		g.process(instead, true)
+
+		below := append(append([]part{}, prefix...), part{literal: e})
+		below = append(below, remain...)
+		g.process(below, true)
+	}
+}
+
+// Returns the names in the directory abs, following symlinks.
+func (g *globber) readdir(abs string) ([]string, bool) {
+	if entries, ok := g.dirs[abs]; ok {
+		return entries, entries != nil
+	}
+
+	var entries []string
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		f, err := os.Open(abs)
+		if err == nil {
+			entries, err = f.Readdirnames(-1)
+			f.Close()
+		}
+		if err != nil {
+			log.Printf("glob error: %s\n", err)
+			entries = nil
+		} else if entries == nil {
+			entries = []string{}
+		}
+	}
+	sort.Strings(entries)
+
+	g.dirs[abs] = entries
+	return entries, entries != nil
+}
+
+func isSymlink(abs string) bool {
+	info, err := os.Lstat(abs)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
@@ -0,0 +1,422 @@
+package glob
+
+import (
+	"regexp"
+	"strings"
+)
+
+type nodeKind int
+
+const (
+	nodeLiteral nodeKind = iota // a single character
+	nodeAny                     // ?
+	nodeStar                    // *
+	nodeClass                   // [...]
+	nodeExtglob                 // ?(...) *(...) +(...) @(...) !(...)
+)
+
+// node is an element of a compiled path segment.
+type node struct {
+	kind  nodeKind
+	char  rune
+	class *charClass
+	ext   rune
+	alts  [][]node
+}
+
+// segment matches a single path component.
+type segment struct {
+	raw   string
+	nodes []node
+}
+
+// part is a path component of a pattern; either a literal
+// name, a globstar (**), or a segment containing magic.
+type part struct {
+	literal  string
+	globstar bool
+	segment  *segment
+}
+
+func (p part) isLiteral() bool {
+	return !p.globstar && p.segment == nil
+}
+
+// Characters escaped by minimatch when building a regular expression.
+const reSpecials = "().*{}+?[]^$\\!"
+
+var (
+	slashSplit    = regexp.MustCompile(`/+`)
+	globUnescaper = regexp.MustCompile(`\\(.)`)
+)
+
+// Compiles a pattern into its brace expanded set of path components.
+func compile(pattern string) [][]part {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil
+	}
+
+	var set [][]part
+	for _, expanded := range expandBraces(pattern) {
+		var parts []part
+		for _, p := range slashSplit.Split(expanded, -1) {
+			parts = append(parts, parsePart(p))
+		}
+		set = append(set, parts)
+	}
+	return set
+}
+
+func parsePart(p string) part {
+	if p == "**" {
+		return part{globstar: true}
+	}
+	nodes, magic := parseSegment(p)
+	if !magic {
+		return part{literal: globUnescaper.ReplaceAllString(p, "$1")}
+	}
+	return part{segment: &segment{raw: p, nodes: nodes}}
+}
+
+func literal(c rune) node {
+	return node{kind: nodeLiteral, char: c}
+}
+
+// frame is an extglob being parsed.
+type frame struct {
+	kind  rune
+	outer []node
+	alts  [][]node
+}
+
+// parseSegment compiles a single path component and reports
+// whether it contains any magic. It follows minimatch's parser,
+// including how it recovers from unterminated classes and extglobs.
+func parseSegment(pattern string) ([]node, bool) {
+	src := []rune(pattern)
+
+	var (
+		cur      []node
+		frames   []*frame
+		state    rune
+		escaping bool
+		magic    bool
+	)
+
+	clearState := func() {
+		switch state {
+		case 0:
+			return
+		case '*':
+			cur = append(cur, node{kind: nodeStar})
+			magic = true
+		case '?':
+			cur = append(cur, node{kind: nodeAny})
+			magic = true
+		default:
+			cur = append(cur, literal(state))
+		}
+		state = 0
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+
+		if escaping {
+			cur = append(cur, literal(c))
+			escaping = false
+			continue
+		}
+
+		switch c {
+		case '\\':
+			clearState()
+			escaping = true
+		case '?', '*', '+', '@', '!':
+			clearState()
+			state = c
+		case '(':
+			if state == 0 {
+				cur = append(cur, literal(c))
+				continue
+			}
+			frames = append(frames, &frame{kind: state, outer: cur})
+			cur, state = nil, 0
+		case ')':
+			if len(frames) == 0 {
+				cur = append(cur, literal(c))
+				continue
+			}
+			clearState()
+			magic = true
+			f := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			alts := append(f.alts, cur)
+			cur = append(f.outer, node{kind: nodeExtglob, ext: f.kind, alts: alts})
+		case '|':
+			if len(frames) == 0 {
+				cur = append(cur, literal(c))
+				continue
+			}
+			clearState()
+			f := frames[len(frames)-1]
+			f.alts = append(f.alts, cur)
+			cur = nil
+		case '[':
+			clearState()
+			end := classEnd(src, i)
+			if end < 0 {
+				// the bracket is literal and the remainder is
+				// parsed as a pattern of its own
+				sub, subMagic := parseSegment(string(src[i+1:]))
+				cur = append(cur, literal('['))
+				cur = append(cur, sub...)
+				magic = magic || subMagic
+				i = len(src)
+				continue
+			}
+			if class, ok := parseClass(src[i+1 : end]); ok {
+				cur = append(cur, node{kind: nodeClass, class: class})
+				magic = true
+			} else {
+				sub, subMagic := parseSegment(string(src[i+1 : end]))
+				cur = append(cur, literal('['))
+				cur = append(cur, sub...)
+				cur = append(cur, literal(']'))
+				magic = magic || subMagic
+			}
+			i = end
+		case ']':
+			cur = append(cur, literal(c))
+		default:
+			clearState()
+			cur = append(cur, literal(c))
+		}
+	}
+
+	// unterminated extglobs are literal, apart from a leading * or ?
+	for len(frames) > 0 {
+		f := frames[len(frames)-1]
+		frames = frames[:len(frames)-1]
+
+		next := append([]node{}, f.outer...)
+		switch f.kind {
+		case '*':
+			next = append(next, node{kind: nodeStar})
+		case '?':
+			next = append(next, node{kind: nodeAny})
+		default:
+			next = append(next, literal(f.kind))
+		}
+		next = append(next, literal('('))
+		for _, alt := range f.alts {
+			next = append(next, alt...)
+			next = append(next, literal('|'))
+		}
+		cur = append(next, cur...)
+		magic = true
+	}
+
+	clearState()
+	if escaping {
+		cur = append(cur, literal('\\'))
+	}
+	return cur, magic
+}
+
+// Returns the index of the bracket closing the class opened at start,
+// or -1. A bracket directly after the opening one is part of the class.
+func classEnd(src []rune, start int) int {
+	for j := start + 1; j < len(src); j++ {
+		switch {
+		case src[j] == '\\':
+			j++
+		case src[j] == ']' && j != start+1:
+			return j
+		}
+	}
+	return -1
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+// charClass is a bracket expression such as [a-z] or [!_].
+type charClass struct {
+	negate bool
+	ranges []runeRange
+}
+
+func (c *charClass) matches(r rune) bool {
+	in := false
+	for _, rr := range c.ranges {
+		if r >= rr.lo && r <= rr.hi {
+			in = true
+			break
+		}
+	}
+	return in != c.negate
+}
+
+type classToken struct {
+	r       rune
+	escaped bool
+}
+
+// parseClass compiles the content of a bracket expression. It reports
+// false when the class is invalid, in which case the brackets are
+// treated literally.
+func parseClass(content []rune) (*charClass, bool) {
+	var raw []classToken
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\\' && i+1 < len(content) {
+			i++
+			raw = append(raw, classToken{content[i], true})
+		} else {
+			raw = append(raw, classToken{content[i], false})
+		}
+	}
+
+	// ranges out of order are rejected
+	for k := 0; k+2 < len(raw); k++ {
+		if raw[k+1].r != '-' || raw[k+1].escaped {
+			continue
+		}
+		if isClassEscape(raw[k]) || isClassEscape(raw[k+2]) {
+			k += 2
+			continue
+		}
+		if raw[k].r > raw[k+2].r {
+			return nil, false
+		}
+		k += 2
+	}
+
+	// minimatch only keeps escapes in front of its special characters,
+	// and turns a leading ! into ^
+	tokens := make([]classToken, len(raw))
+	for k, t := range raw {
+		if t.escaped && !strings.ContainsRune(reSpecials, t.r) {
+			t.escaped = false
+		}
+		if k == 0 && !t.escaped && t.r == '!' {
+			t.r = '^'
+		}
+		tokens[k] = t
+	}
+
+	class := &charClass{}
+	if len(tokens) > 0 && !tokens[0].escaped && tokens[0].r == '^' {
+		class.negate = true
+		tokens = tokens[1:]
+	}
+	for k := 0; k < len(tokens); k++ {
+		lo := tokens[k].r
+		if k+2 < len(tokens) && tokens[k+1].r == '-' && !tokens[k+1].escaped {
+			hi := tokens[k+2].r
+			if lo > hi {
+				// the resulting expression is invalid and matches nothing
+				return &charClass{}, true
+			}
+			class.ranges = append(class.ranges, runeRange{lo, hi})
+			k += 2
+			continue
+		}
+		class.ranges = append(class.ranges, runeRange{lo, lo})
+	}
+	return class, true
+}
+
+func isClassEscape(t classToken) bool {
+	return t.escaped && strings.ContainsRune("dDwWsS", t.r)
+}
+
+func (s *segment) match(name string) bool {
+	src := []rune(name)
+	return matchNodes(s.nodes, src, 0, true, func(i int) bool {
+		return i == len(src)
+	})
+}
+
+// matchNodes matches nodes against s from position i, calling k with
+// the end position of each match until k accepts one.
+func matchNodes(nodes []node, s []rune, i int, top bool, k func(int) bool) bool {
+	if len(nodes) == 0 {
+		return k(i)
+	}
+
+	n, rest := nodes[0], nodes[1:]
+	next := func(j int) bool {
+		return matchNodes(rest, s, j, top, k)
+	}
+
+	switch n.kind {
+	case nodeLiteral:
+		return i < len(s) && s[i] == n.char && next(i+1)
+	case nodeAny:
+		return i < len(s) && next(i+1)
+	case nodeClass:
+		return i < len(s) && n.class.matches(s[i]) && next(i+1)
+	case nodeStar:
+		for j := i; j <= len(s); j++ {
+			if next(j) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch n.ext {
+	case '@':
+		return matchAlts(n.alts, s, i, next)
+	case '?':
+		return next(i) || matchAlts(n.alts, s, i, next)
+	case '*', '+':
+		min := 0
+		if n.ext == '+' {
+			min = 1
+		}
+		var repeat func(count, j int) bool
+		repeat = func(count, j int) bool {
+			if count >= min && next(j) {
+				return true
+			}
+			return matchAlts(n.alts, s, j, func(e int) bool {
+				if e == j && count >= min {
+					return false
+				}
+				return repeat(count+1, e)
+			})
+		}
+		return repeat(0, i)
+	default: // '!'
+		// none of the alternatives followed by the rest of the
+		// pattern may match here, then anything goes
+		lookahead := k
+		if top && len(rest) > 0 {
+			lookahead = func(int) bool { return true }
+		}
+		if matchAlts(n.alts, s, i, func(j int) bool {
+			return matchNodes(rest, s, j, top, lookahead)
+		}) {
+			return false
+		}
+		for j := i; j <= len(s); j++ {
+			if next(j) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchAlts(alts [][]node, s []rune, i int, k func(int) bool) bool {
+	for _, alt := range alts {
+		if matchNodes(alt, s, i, false, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 
 	"github.com/cdnjs/tools/compress"
 	"github.com/cdnjs/tools/packages"
 	"github.com/cdnjs/tools/util"
+	"github.com/cdnjs/tools/version"
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 )
@@ -112,7 +114,15 @@ func UpdateAggregatedMetadata(api *cloudflare.API, ctx context.Context,
 		}
 		found = true
 	}
-	aggPkg.Version = &newVersion
+	// keep assets ordered from the greatest to the lowest version, the
+	// new version not necessarily being the latest one (ex. backports)
+	sort.Sort(version.ByVersionAsset(aggPkg.Assets))
+
+	var versions []string
+	for _, asset := range aggPkg.Assets {
+		versions = append(versions, asset.Version)
+	}
+	aggPkg.Version = version.GetLatestStableVersion(versions)
 
 	successfulWrites, err := writeAggregatedMetadata(ctx, api, aggPkg)
 	return successfulWrites, found, err
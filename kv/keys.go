@@ -0,0 +1,17 @@
+package kv
+
+import "path"
+
+// VersionKey returns the key of the entry of a version of a library,
+// ex. `a-happy-tyler/1.0.0`. Keys are built from the name of the library,
+// never from its autoupdate target, since a scoped npm target, ex.
+// `@scope/name`, would add a level to the keys.
+func VersionKey(name, version string) string {
+	return path.Join(name, version)
+}
+
+// FileKey returns the key of a file of a version of a library in the KV
+// and R2 stores, ex. `a-happy-tyler/1.0.0/dist/a.js`.
+func FileKey(name, version, file string) string {
+	return name + "/" + version + "/" + file
+}
@@ -3,7 +3,6 @@ package kv
 import (
 	"context"
 	"encoding/json"
-	"path"
 	"strings"
 
 	"github.com/cdnjs/tools/util"
@@ -43,7 +42,7 @@ func GetVersion(ctx context.Context, api *cloudflare.API, key string) ([]string,
 // // Note: for now, a `version` entry is just a []string of assets, but this could become
 // // a struct if more metadata is added.
 func updateVersionRequest(pkg, version string, files []string) WriteRequest {
-	key := path.Join(pkg, version)
+	key := VersionKey(pkg, version)
 
 	v, err := json.Marshal(files)
 	util.Check(err)
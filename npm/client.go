@@ -0,0 +1,87 @@
+package npm
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var (
+	// Base URL of the registry, the public one if empty.
+	NPM_REGISTRY_URL = os.Getenv("NPM_REGISTRY_URL")
+	// Base URL of the downloads API, the public one if empty.
+	NPM_DOWNLOADS_URL = os.Getenv("NPM_DOWNLOADS_URL")
+	// Token sent to the registry, if set.
+	NPM_TOKEN = os.Getenv("NPM_TOKEN")
+)
+
+const (
+	publicRegistryURL  = "https://registry.npmjs.org"
+	publicDownloadsURL = "https://api.npmjs.org/downloads"
+
+	// The abbreviated metadata of a package, with what is needed to install
+	// its versions only, falling back to the full metadata.
+	abbreviatedFormat = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8, */*"
+	fullFormat        = "application/json"
+)
+
+// Client queries an npm registry, and the npm downloads API.
+type Client struct {
+	// RegistryURL is the base URL of the registry, ex. `https://registry.npmjs.org`.
+	RegistryURL string
+	// DownloadsURL is the base URL of the downloads API, ex. `https://api.npmjs.org/downloads`.
+	DownloadsURL string
+	// Token, if set, is sent to the registry as a bearer token.
+	Token string
+}
+
+// NewClient creates a client of a registry, authenticated with
+// a token if not empty. Downloads are queried on the public API.
+func NewClient(registryURL, token string) *Client {
+	return &Client{
+		RegistryURL:  strings.TrimSuffix(registryURL, "/"),
+		DownloadsURL: publicDownloadsURL,
+		Token:        token,
+	}
+}
+
+// DefaultClient creates a client of the registry at NPM_REGISTRY_URL,
+// authenticated with NPM_TOKEN, and of the downloads API at NPM_DOWNLOADS_URL.
+// The public registry and API are used by default.
+func DefaultClient() *Client {
+	c := NewClient(publicRegistryURL, NPM_TOKEN)
+	if NPM_REGISTRY_URL != "" {
+		c.RegistryURL = strings.TrimSuffix(NPM_REGISTRY_URL, "/")
+	}
+	if NPM_DOWNLOADS_URL != "" {
+		c.DownloadsURL = strings.TrimSuffix(NPM_DOWNLOADS_URL, "/")
+	}
+	return c
+}
+
+// Returns the URL of the metadata of a package on the registry.
+func (c *Client) packageURL(name string) string {
+	return c.RegistryURL + "/" + escapeName(name)
+}
+
+// Returns the URL of the downloads of a package in a period, ex. `last-month`.
+func (c *Client) downloadsURL(period, name string) string {
+	return c.DownloadsURL + "/point/" + period + "/" + escapeName(name)
+}
+
+// Escapes the name of a package in a URL path, where the slash of
+// scoped packages is encoded, ex. `@scope%2Fname` for `@scope/name`.
+func escapeName(name string) string {
+	return url.PathEscape(name)
+}
+
+// Returns the headers of a request to the registry, for a format.
+func (c *Client) registryHeader(format string) http.Header {
+	header := make(http.Header)
+	header.Set("Accept", format)
+	if c.Token != "" {
+		header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return header
+}
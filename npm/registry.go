@@ -3,15 +3,17 @@ package npm
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cdnjs/tools/packages"
 	"github.com/cdnjs/tools/util"
 	"github.com/cdnjs/tools/version"
+
+	"github.com/pkg/errors"
 )
 
 // Registry contains metadata about a particular npm package.
@@ -27,74 +29,186 @@ type MonthlyDownload struct {
 	Downloads uint `json:"downloads"`
 }
 
-// Exists determines if an npm package exists.
-func Exists(name string) bool {
-	resp, err := http.Get(util.GetProtocol() + "://registry.npmjs.org/" + name)
-	util.Check(err)
-	return resp.StatusCode == http.StatusOK
+// Exists determines if an npm package exists, using its abbreviated metadata.
+// The response is shared through util.CachedGet.
+func (c *Client) Exists(name string) (bool, error) {
+	resp, err := util.CachedGet(c.packageURL(name), c.registryHeader(abbreviatedFormat))
+	if err != nil {
+		return false, err
+	}
+	return resp.StatusCode == http.StatusOK, nil
 }
 
 // GetMonthlyDownload uses the npm API to get the MonthlyDownload
 // for a particular npm package.
-func GetMonthlyDownload(name string) MonthlyDownload {
-	resp, err := http.Get(util.GetProtocol() + "://api.npmjs.org/downloads/point/last-month/" + name)
-	util.Check(err)
+// The response is shared through util.CachedGet.
+func (c *Client) GetMonthlyDownload(name string) (MonthlyDownload, error) {
+	var counts MonthlyDownload
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	util.Check(err)
+	resp, err := util.CachedGet(c.downloadsURL("last-month", name), nil)
+	if err != nil {
+		return counts, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return counts, nil
+	}
+	if err := json.Unmarshal(resp.Body, &counts); err != nil {
+		return counts, errors.Wrapf(err, "could not parse downloads of %s", name)
+	}
+	return counts, nil
+}
 
-	var counts MonthlyDownload
-	util.Check(json.Unmarshal(body, &counts))
-	return counts
+// GetLicense gets the license declared in the package.json of the latest
+// version of an npm package, or an empty string if there is none.
+// Legacy `licenses` lists are returned as an SPDX disjunction. Licenses
+// aren't part of the abbreviated metadata, so the document of the latest
+// version is fetched, which is much smaller than the full metadata.
+// The response is shared through util.CachedGet.
+func (c *Client) GetLicense(name string) (string, error) {
+	resp, err := util.CachedGet(c.packageURL(name)+"/latest", c.registryHeader(fullFormat))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+
+	var v struct {
+		License  interface{}   `json:"license"`
+		Licenses []interface{} `json:"licenses"`
+	}
+	if err := json.Unmarshal(resp.Body, &v); err != nil {
+		return "", errors.Wrapf(err, "could not parse latest version of %s", name)
+	}
+
+	if v.License != nil {
+		return licenseType(v.License), nil
+	}
+	types := make([]string, 0, len(v.Licenses))
+	for _, l := range v.Licenses {
+		if t := licenseType(l); t != "" {
+			types = append(types, t)
+		}
+	}
+	if len(types) > 1 {
+		return "(" + strings.Join(types, " OR ") + ")", nil
+	}
+	return strings.Join(types, ""), nil
+}
+
+// Returns the type of a license of a package.json, which is either
+// a string or, in old versions, an object with a type and a url.
+func licenseType(license interface{}) string {
+	switch l := license.(type) {
+	case string:
+		return l
+	case map[string]interface{}:
+		if t, ok := l["type"].(string); ok {
+			return t
+		}
+	}
+	return ""
 }
 
 // GetVersions gets all of the versions associated with an npm package,
-// as well as the latest version based on the `latest` tag.
-func GetVersions(ctx context.Context, config *packages.Autoupdate) ([]version.Version, *string) {
+// as well as the latest version based on the `latest` tag, from the
+// abbreviated metadata. Versions aren't dated, since the publication
+// times are only part of the full metadata, which can be tens of MB
+// for big packages. Use DateVersions when they are needed.
+func (c *Client) GetVersions(ctx context.Context, config *packages.Autoupdate) ([]version.Version, *string, error) {
 	name := *config.Target
-	resp, err := http.Get(util.GetProtocol() + "://registry.npmjs.org/" + name)
-	util.Check(err)
-
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	util.Check(err)
-
 	var r Registry
-	util.Check(json.Unmarshal(body, &r))
+	if err := c.getRegistry(ctx, name, abbreviatedFormat, &r); err != nil {
+		return nil, nil, err
+	}
 
 	versions := make([]version.Version, 0)
 	for k, v := range r.Versions {
-		if v, ok := v.(map[string]interface{}); ok {
-			dist := v["dist"].(map[string]interface{})
-			tarball := dist["tarball"].(string)
-
-			if timeInt, ok := r.TimeStamps[k]; ok {
-				if timeStr, ok := timeInt.(string); ok {
-					// parse time.Time from time stamp
-					timeStamp, err := time.Parse(time.RFC3339, timeStr)
-					util.Check(err)
-
-					if !version.IsVersionIgnored(config, k) {
-						versions = append(versions, version.Version{
-							Version: k,
-							Tarball: tarball,
-							Date:    timeStamp,
-							Source:  "npm",
-						})
-					} else {
-						log.Printf("%s: version %s is ignored\n", name, k)
-					}
-					continue
-				}
-			}
-			panic(fmt.Errorf("no time stamp for npm version %s/%s", name, k))
+		v, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dist, ok := v["dist"].(map[string]interface{})
+		if !ok {
+			return nil, nil, errors.Errorf("no dist for npm version %s/%s", name, k)
+		}
+		tarball, ok := dist["tarball"].(string)
+		if !ok {
+			return nil, nil, errors.Errorf("no tarball for npm version %s/%s", name, k)
+		}
+		// digests of the tarball, missing for some old versions
+		integrity, _ := dist["integrity"].(string)
+		shasum, _ := dist["shasum"].(string)
+
+		if !version.IsVersionIgnored(config, k) {
+			versions = append(versions, version.Version{
+				Version:   k,
+				Tarball:   tarball,
+				Source:    "npm",
+				Integrity: integrity,
+				Shasum:    shasum,
+			})
+		} else {
+			log.Printf("%s: version %s is ignored\n", name, k)
 		}
 	}
 
+	versions = version.FilterPrereleases(config, versions)
+
 	// attempt to get latest version according to npm
 	if latest, ok := r.DistTags["latest"]; ok {
-		return versions, &latest
+		return versions, &latest, nil
+	}
+	return versions, nil, nil
+}
+
+// DateVersions sets the publication times of versions of an npm package,
+// which are only part of its full metadata. Since it is much larger than
+// the abbreviated one, it should only be fetched when the times are needed,
+// ex. once new versions are found.
+func (c *Client) DateVersions(ctx context.Context, name string, versions []version.Version) error {
+	var r Registry
+	if err := c.getRegistry(ctx, name, fullFormat, &r); err != nil {
+		return err
+	}
+
+	for i, v := range versions {
+		timeStr, ok := r.TimeStamps[v.Version].(string)
+		if !ok {
+			return errors.Errorf("no time stamp for npm version %s/%s", name, v.Version)
+		}
+		// parse time.Time from time stamp
+		timeStamp, err := time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			return errors.Wrapf(err, "invalid time stamp for npm version %s/%s", name, v.Version)
+		}
+		versions[i].Date = timeStamp
+	}
+	return nil
+}
+
+// Fetches and parses the metadata of an npm package in a format.
+func (c *Client) getRegistry(ctx context.Context, name, format string, r *Registry) error {
+	req, err := http.NewRequest("GET", c.packageURL(name), nil)
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+	req.Header = c.registryHeader(format)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "could not fetch npm package %s", name)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("could not fetch npm package %s: %s", name, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "could not read npm package %s", name)
+	}
+	if err := json.Unmarshal(body, r); err != nil {
+		return errors.Wrapf(err, "could not parse npm package %s", name)
 	}
-	return versions, nil
+	return nil
 }
@@ -0,0 +1,45 @@
+package packages
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// names are quoted in the logs, so they can be parsed back even when they
+// contain spaces, ex. `x (1).js`
+var collisionRegex = regexp.MustCompile(`file ("(?:[^"\\]|\\.)*") ignored, destination ("(?:[^"\\]|\\.)*") is already used by ("(?:[^"\\]|\\.)*")`)
+
+// DestinationCollision represents a file that is not published because
+// another file matched by the fileMap is published at its destination.
+type DestinationCollision struct {
+	From  string
+	To    string
+	Other string
+}
+
+// String is used to satisfy the fmt.Stringer interface. It is the
+// message logged by NpmFilesFrom when a collision is found.
+func (c DestinationCollision) String() string {
+	return fmt.Sprintf("file %q ignored, destination %q is already used by %q", c.From, c.To, c.Other)
+}
+
+// ParseDestinationCollisions finds the collisions reported in the logs
+// of NpmFilesFrom, for instance when it runs in the sandbox.
+func ParseDestinationCollisions(logs string) []DestinationCollision {
+	var collisions []DestinationCollision
+	for _, m := range collisionRegex.FindAllStringSubmatch(logs, -1) {
+		from, err1 := strconv.Unquote(m[1])
+		to, err2 := strconv.Unquote(m[2])
+		other, err3 := strconv.Unquote(m[3])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		collisions = append(collisions, DestinationCollision{
+			From:  from,
+			To:    to,
+			Other: other,
+		})
+	}
+	return collisions
+}
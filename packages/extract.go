@@ -0,0 +1,83 @@
+package packages
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExtractTarball extracts the regular files of the gzipped tarball of a
+// version into dir, without the directory added by its autoupdate source
+// (ex. `package/` on npm, which some packages name differently, like the
+// package itself). Files located outside of dir are ignored.
+func ExtractTarball(r io.Reader, source, dir string) error {
+	uncompressedStream, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, "could not create reader")
+	}
+
+	tarReader := tar.NewReader(uncompressedStream)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "ExtractTarGz: Next() failed")
+		}
+
+		target := header.Name
+		if source == "npm" || source == "git" {
+			// remove package folder, whatever its name, like npm
+			target = removeFirstDir(header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			// ignore dirs
+		case tar.TypeReg:
+			dest := path.Join(dir, target)
+			if !strings.HasPrefix(dest, path.Clean(dir)+"/") {
+				log.Printf("Unsafe file located outside `%s` with name: `%s`\n", dir, header.Name)
+				continue
+			}
+			if err := extractFile(tarReader, dest); err != nil {
+				return err
+			}
+		default:
+			log.Printf(
+				"ExtractTarGz: uknown type: %x in %s\n",
+				header.Typeflag,
+				header.Name)
+		}
+	}
+	return nil
+}
+
+func extractFile(r io.Reader, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return errors.Wrap(err, "ExtractTarGz: Mkdir() failed")
+	}
+	outFile, err := os.Create(dest)
+	if err != nil {
+		return errors.Wrap(err, "ExtractTarGz: Create() failed")
+	}
+	defer outFile.Close()
+	if _, err := io.Copy(outFile, r); err != nil {
+		return errors.Wrap(err, "ExtractTarGz: Copy() failed")
+	}
+	return nil
+}
+
+func removeFirstDir(path string) string {
+	parts := strings.Split(path, "/")
+	return strings.Replace(path, parts[0]+"/", "", 1)
+}
@@ -0,0 +1,113 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// ex. git@github.com:cdnjs/tools.git
+	scpURLRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+@([a-zA-Z0-9.-]+):(.+)$`)
+	// ex. github:cdnjs/tools
+	shorthandURLRegex = regexp.MustCompile(`^(github|gitlab|bitbucket):([a-zA-Z0-9._-]+/[a-zA-Z0-9._-]+)$`)
+
+	shorthandHosts = map[string]string{
+		"github":    "github.com",
+		"gitlab":    "gitlab.com",
+		"bitbucket": "bitbucket.org",
+	}
+)
+
+// NormalizeRepositoryURL returns the canonical form of a git repository
+// URL, which is `https://<host>/<path>.git`. Shorthands (ex. `github:user/repo`),
+// scp-like URLs (ex. `git@github.com:user/repo.git`) and the `git+https://`,
+// `git://` and `http://` schemes are rewritten. Other URLs are left as is.
+func NormalizeRepositoryURL(url string) string {
+	url = strings.TrimSpace(url)
+
+	if m := shorthandURLRegex.FindStringSubmatch(url); m != nil {
+		url = "https://" + shorthandHosts[m[1]] + "/" + m[2]
+	} else if m := scpURLRegex.FindStringSubmatch(url); m != nil && !strings.Contains(url, "://") {
+		url = "https://" + m[1] + "/" + m[2]
+	}
+
+	url = strings.TrimPrefix(url, "git+")
+	for _, scheme := range []string{"git://", "http://", "ssh://git@"} {
+		if strings.HasPrefix(url, scheme) {
+			url = "https://" + strings.TrimPrefix(url, scheme)
+		}
+	}
+	if !strings.HasPrefix(url, "https://") {
+		return url
+	}
+
+	url = strings.TrimRight(url, "/")
+	host := strings.SplitN(strings.TrimPrefix(url, "https://"), "/", 2)[0]
+	url = "https://" + strings.ToLower(host) + strings.TrimPrefix(url, "https://"+host)
+	if !strings.HasSuffix(url, ".git") {
+		url += ".git"
+	}
+	return url
+}
+
+// FormatHumanJSONBytes validates a human-readable package and returns
+// its canonical form: properties in the order of the Package structure,
+// indented with 2 spaces, sorted and deduplicated keywords, and a
+// normalized git repository URL.
+func FormatHumanJSONBytes(ctx context.Context, file string, b []byte) ([]byte, error) {
+	// duplicated keywords are removed before the validation,
+	// which would otherwise reject them
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err == nil {
+		if keywords, ok := raw["keywords"].([]interface{}); ok {
+			raw["keywords"] = dedupe(keywords)
+			if deduped, err := json.Marshal(raw); err == nil {
+				b = deduped
+			}
+		}
+	}
+
+	p, err := ReadHumanJSONBytes(ctx, file, b, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// derived from `authors`
+	p.Author = nil
+
+	sort.Strings(p.Keywords)
+
+	if p.Repository != nil && p.Repository.Type != nil && *p.Repository.Type == "git" {
+		url := NormalizeRepositoryURL(*p.Repository.URL)
+		p.Repository.URL = &url
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(p); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Removes the duplicated strings of a JSON array.
+func dedupe(items []interface{}) []interface{} {
+	var out []interface{}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+		}
+		out = append(out, item)
+	}
+	return out
+}
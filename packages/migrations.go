@@ -0,0 +1,152 @@
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Migration is a versioned transform of a raw human-readable package,
+// used to update package files when the schema evolves.
+type Migration struct {
+	Version     int
+	Description string
+	// Migrate transforms the package in place
+	// and returns whether it changed.
+	Migrate func(pkg map[string]interface{}) bool
+}
+
+// Migrations are the migrations ordered by version.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "move the legacy npmName and npmFileMap into autoupdate",
+		Migrate:     migrateLegacyNpm,
+	},
+	{
+		Version:     2,
+		Description: "replace the legacy author string by authors",
+		Migrate:     migrateLegacyAuthor,
+	},
+	{
+		Version:     3,
+		Description: "replace a repository URL string by a repository object",
+		Migrate:     migrateRepositoryString,
+	},
+}
+
+// LatestMigration returns the version of the last migration.
+func LatestMigration() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// MigrateHumanJSONBytes applies the migrations with a version greater than
+// from and up to to. If any migration changed the package, it is validated
+// and returned in its canonical form, along with the applied migrations.
+// Otherwise the package is returned as is.
+func MigrateHumanJSONBytes(ctx context.Context, file string, b []byte, from, to int) ([]byte, []Migration, error) {
+	var pkg map[string]interface{}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse %s", file)
+	}
+
+	var applied []Migration
+	for _, m := range Migrations {
+		if m.Version > from && m.Version <= to && m.Migrate(pkg) {
+			applied = append(applied, m)
+		}
+	}
+	if len(applied) == 0 {
+		return b, nil, nil
+	}
+
+	migrated, err := json.Marshal(pkg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal migrated package")
+	}
+	formatted, err := FormatHumanJSONBytes(ctx, file, migrated)
+	if err != nil {
+		return nil, applied, err
+	}
+	return formatted, applied, nil
+}
+
+// Moves the npmName and npmFileMap used before autoupdate
+// supported git into an npm autoupdate.
+func migrateLegacyNpm(pkg map[string]interface{}) bool {
+	name, hasName := pkg["npmName"]
+	fileMap, hasFileMap := pkg["npmFileMap"]
+	if !hasName && !hasFileMap {
+		return false
+	}
+	delete(pkg, "npmName")
+	delete(pkg, "npmFileMap")
+
+	if _, ok := pkg["autoupdate"]; !ok && hasName && hasFileMap {
+		pkg["autoupdate"] = map[string]interface{}{
+			"source":  "npm",
+			"target":  name,
+			"fileMap": fileMap,
+		}
+	}
+	return true
+}
+
+// ex. `Tyler Caslin <tylercaslin47@gmail.com> (https://github.com/tc80)`
+var authorRegex = regexp.MustCompile(`^([^<(]+?)\s*(?:<([^>]*)>)?\s*(?:\(([^)]*)\))?$`)
+
+// Replaces the legacy author, either a string as formatted by
+// parseAuthor or an object, by authors.
+func migrateLegacyAuthor(pkg map[string]interface{}) bool {
+	author, ok := pkg["author"]
+	if !ok {
+		return false
+	}
+	delete(pkg, "author")
+
+	if _, ok := pkg["authors"]; ok {
+		return true
+	}
+
+	var authors []interface{}
+	switch author := author.(type) {
+	case string:
+		for _, s := range strings.Split(author, ",") {
+			m := authorRegex.FindStringSubmatch(strings.TrimSpace(s))
+			if m == nil {
+				continue
+			}
+			a := map[string]interface{}{"name": m[1]}
+			if m[2] != "" {
+				a["email"] = m[2]
+			}
+			if m[3] != "" {
+				a["url"] = m[3]
+			}
+			authors = append(authors, a)
+		}
+	case map[string]interface{}:
+		authors = append(authors, author)
+	}
+	if len(authors) > 0 {
+		pkg["authors"] = authors
+	}
+	return true
+}
+
+// Replaces a repository URL string, as allowed by npm,
+// by a git repository object.
+func migrateRepositoryString(pkg map[string]interface{}) bool {
+	url, ok := pkg["repository"].(string)
+	if !ok {
+		return false
+	}
+	pkg["repository"] = map[string]interface{}{
+		"type": "git",
+		"url":  NormalizeRepositoryURL(url),
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package packages
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	libraryNameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// IsScoped returns whether the name of an npm package
+// has a scope, ex. `@scope/name`.
+func IsScoped(npmName string) bool {
+	return strings.HasPrefix(npmName, "@") && strings.Contains(npmName, "/")
+}
+
+// IsLibraryName returns whether a name is a valid library name on cdnjs,
+// which is used as is in the URLs of its files and in the keys of the
+// KV and R2 stores.
+func IsLibraryName(name string) bool {
+	return libraryNameRegex.MatchString(name)
+}
+
+// LibraryName maps a name, ex. the one of an npm package, onto a valid
+// library name on cdnjs, ex. `scope-name` for `@scope/name`.
+func LibraryName(name string) string {
+	name = strings.TrimPrefix(name, "@")
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-")
+}
+
+// SuggestedName returns a valid library name for a package, mapped from
+// its scoped npm target if any, since the npm name is usually copied
+// into the name of the package, or from its name otherwise.
+func SuggestedName(p *Package) string {
+	if target, ok := ScopedNpmTarget(p); ok {
+		return LibraryName(target)
+	}
+	if p.Name != nil {
+		return LibraryName(*p.Name)
+	}
+	return ""
+}
+
+// ScopedNpmTarget returns the target of a package
+// updated from a scoped npm package, if any.
+func ScopedNpmTarget(p *Package) (string, bool) {
+	a := p.Autoupdate
+	if a == nil || a.Source == nil || *a.Source != "npm" || a.Target == nil || !IsScoped(*a.Target) {
+		return "", false
+	}
+	return *a.Target, true
+}
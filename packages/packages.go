@@ -9,34 +9,55 @@ import (
 	"path"
 
 	"github.com/cdnjs/tools/util"
-
-	"github.com/blang/semver"
 )
 
 // Author represents an author.
 type Author struct {
-	Name  *string `json:"name,omitempty"`
-	Email *string `json:"email,omitempty"`
-	URL   *string `json:"url,omitempty"`
+	Name  *string `json:"name,omitempty" schema:"required,minLength=1"`
+	Email *string `json:"email,omitempty" schema:"minLength=1"`
+	URL   *string `json:"url,omitempty" schema:"minLength=1"`
 }
 
 // Autoupdate is used to update particular files from
 // a source type located at a target destination.
 type Autoupdate struct {
-	Source            *string   `json:"source,omitempty"`
-	Target            *string   `json:"target,omitempty"`
-	FileMap           []FileMap `json:"fileMap,omitempty"`
+	Source            *string   `json:"source,omitempty" schema:"required" pattern:"^git|npm$"`
+	Target            *string   `json:"target,omitempty" schema:"required,minLength=1"`
+	FileMap           []FileMap `json:"fileMap,omitempty" schema:"required,minItems=1,uniqueItems"`
 	IgnoreVersions    []string  `json:"ignoreVersions,omitempty"`
-	ExcludeFromSearch *bool     `json:"excludeFromSearch,omitempty"`
+	VersionRange      *string   `json:"versionRange,omitempty" schema:"minLength=1"`
+	Prerelease        *string   `json:"prerelease,omitempty" schema:"" pattern:"^(stable|latest-prerelease|all)$"`
+	ExcludeFromSearch *bool     `json:"excludeFromSearch,omitempty" schema:""`
+}
+
+// Prerelease policies, used to select which prerelease
+// versions are imported.
+const (
+	// PrereleaseStable only imports stable versions.
+	PrereleaseStable = "stable"
+	// PrereleaseLatest imports stable versions and the latest prerelease
+	// version, if it is more recent than the latest stable version.
+	PrereleaseLatest = "latest-prerelease"
+	// PrereleaseAll imports all versions.
+	PrereleaseAll = "all"
+)
+
+// PrereleasePolicy returns the prerelease policy, which
+// defaults to importing all versions.
+func (a *Autoupdate) PrereleasePolicy() string {
+	if a.Prerelease == nil {
+		return PrereleaseAll
+	}
+	return *a.Prerelease
 }
 
 // Optimization is used to enable/disable optimization
 // for particular file types. By default, we will optimize all files.
 type Optimization struct {
-	JS  *bool `json:"js,omitempty"`
-	CSS *bool `json:"css,omitempty"`
-	PNG *bool `json:"png,omitempty"`
-	JPG *bool `json:"jpg,omitempty"`
+	JS  *bool `json:"js,omitempty" schema:""`
+	CSS *bool `json:"css,omitempty" schema:""`
+	PNG *bool `json:"png,omitempty" schema:""`
+	JPG *bool `json:"jpg,omitempty" schema:""`
 }
 
 // Js returns if we should optimize JavaScript files.
@@ -60,16 +81,33 @@ func (o *Optimization) Jpg() bool {
 }
 
 // FileMap represents a number of files located
-// under a base path.
+// under a base path, optionally published under a destination.
 type FileMap struct {
-	BasePath *string  `json:"basePath"` // can be empty
-	Files    []string `json:"files,omitempty"`
+	BasePath    *string  `json:"basePath" schema:"required"` // can be empty
+	Files       []string `json:"files,omitempty" schema:"required,minItems=1,uniqueItems,itemMinLength=1"`
+	Destination *string  `json:"destination,omitempty" schema:"" pattern:"^[a-zA-Z0-9_@+-][a-zA-Z0-9_@+.-]*(/[a-zA-Z0-9_@+-][a-zA-Z0-9_@+.-]*)*$"`
+}
+
+// DestinationOf returns where a file matched relative to
+// the base path is published.
+func (f FileMap) DestinationOf(file string) string {
+	if f.Destination == nil {
+		return file
+	}
+	return path.Join(*f.Destination, file)
 }
 
 // Repository represents a repository.
 type Repository struct {
-	Type *string `json:"type,omitempty"`
-	URL  *string `json:"url,omitempty"`
+	Type *string `json:"type,omitempty" schema:"required" pattern:"^git|hg|svn$"`
+	URL  *string `json:"url,omitempty" schema:"required,minLength=1"`
+}
+
+// Suppression silences a checker warning for a package,
+// for instance when a package legitimately has no filename.
+type Suppression struct {
+	Code          *string `json:"code,omitempty" schema:"required" pattern:"^[a-z0-9-]+$"`
+	Justification *string `json:"justification,omitempty" schema:"required,minLength=1"`
 }
 
 // Package holds metadata about a package.
@@ -80,22 +118,23 @@ type Package struct {
 	ctx context.Context // context
 
 	// human-readable properties
-	Authors      []Author      `json:"authors,omitempty"`
-	Autoupdate   *Autoupdate   `json:"autoupdate,omitempty"`
-	Optimization *Optimization `json:"optimization,omitempty"`
-	Description  *string       `json:"description,omitempty"`
-	Filename     *string       `json:"filename,omitempty"`
-	Homepage     *string       `json:"homepage,omitempty"`
-	Keywords     []string      `json:"keywords,omitempty"`
-	License      *string       `json:"license,omitempty"`
-	Name         *string       `json:"name,omitempty"`
-	Repository   *Repository   `json:"repository,omitempty"`
+	Authors      []Author      `json:"authors,omitempty" schema:"human,minItems=1,uniqueItems" description:"The attributed author for the library, as defined in the cdnjs package JSON file for this library."`
+	Autoupdate   *Autoupdate   `json:"autoupdate,omitempty" schema:"human,required" description:"Subscribes the package to an autoupdating service when a new version is released."`
+	Optimization *Optimization `json:"optimization,omitempty" schema:"human" description:"Used to enable/disable optimization for particular file types. By default, optimization is enabled for all types."`
+	Description  *string       `json:"description,omitempty" schema:"human,required,nonHumanRequired,minLength=1" description:"The description of the library if it has been provided in the cdnjs package JSON file."`
+	Filename     *string       `json:"filename,omitempty" schema:"human,minLength=1" description:"This will be the name of the default file for the library."`
+	Homepage     *string       `json:"homepage,omitempty" schema:"human,minLength=1" description:"A link to the homepage of the package, if one is defined in the cdnjs package JSON file. Normally, this is either the package repository or the package website."`
+	Keywords     []string      `json:"keywords,omitempty" schema:"human,required,nonHumanRequired,minItems=1,uniqueItems,itemMinLength=1" description:"An array of keywords provided in the cdnjs package JSON for the library."`
+	License      *string       `json:"license,omitempty" schema:"human" pattern:"^(\\(.+ (OR|AND) .+\\)|[a-zA-Z0-9-].*)$" description:"The license defined for the library on cdnjs, as a string. If the library has a custom license, it may not be shown here."`
+	Name         *string       `json:"name,omitempty" schema:"human,required,nonHumanRequired" pattern:"^[a-zA-Z0-9._-]+$" description:"This will be the full name of the library, as stored on cdnjs."`
+	Repository   *Repository   `json:"repository,omitempty" schema:"human,required" description:"The repository for the library, if known, in standard repository format."`
+	Suppressions []Suppression `json:"suppressions,omitempty" schema:"human,minItems=1,uniqueItems" description:"The checker warnings which don't apply to the library, each with a justification."`
 
 	// additional properties
-	Version *string `json:"version,omitempty"`
+	Version *string `json:"version,omitempty" schema:"nonHuman,nonHumanRequired,minLength=1"`
 
 	// legacy
-	Author *string `json:"author,omitempty"`
+	Author *string `json:"author,omitempty" schema:"nonHuman,minLength=1"`
 
 	// for aggregated metadata entries
 	Assets []Asset `json:"assets,omitempty"`
@@ -162,11 +201,35 @@ func (p *Package) RemoveVersion(name string) {
 // NpmFilesFrom lists files that match the npm glob pattern in the `base` directory
 // Returns a struct that represent the move semantics
 func (p *Package) NpmFilesFrom(base string) []NpmFileMoveOp {
+	out, collisions := p.NpmFilesAndCollisionsFrom(base)
+	for _, collision := range collisions {
+		log.Println(collision)
+	}
+	return out
+}
+
+// NpmFilesAndCollisionsFrom is NpmFilesFrom, also returning the files
+// which are ignored since their destination is already used.
+//
+// When one of two colliding files is matched by a fileMap with a destination,
+// the first one is published and the later one is left out. Otherwise, both
+// are listed like before destinations existed, so the last one overwrites the
+// earlier one when they are moved, and the collision reports the earlier one.
+func (p *Package) NpmFilesAndCollisionsFrom(base string) ([]NpmFileMoveOp, []DestinationCollision) {
 	out := make([]NpmFileMoveOp, 0)
+	var collisions []DestinationCollision
 
 	// map used to determine if a file path has already been processed
 	seen := make(map[string]bool)
 
+	// map of destinations to the file published there, and
+	// whether its fileMap has a destination
+	type published struct {
+		from     string
+		remapped bool
+	}
+	dests := make(map[string]published)
+
 	for _, fileMap := range p.Autoupdate.FileMap {
 		for _, pattern := range fileMap.Files {
 			basePath := path.Join(base, *fileMap.BasePath)
@@ -197,16 +260,30 @@ func (p *Package) NpmFilesFrom(base string) []NpmFileMoveOp {
 					continue
 				}
 
+				from, to := path.Join(*fileMap.BasePath, f), fileMap.DestinationOf(f)
+
+				remapped := fileMap.Destination != nil
+				if other, ok := dests[to]; ok {
+					// ignore files colliding with a file already published
+					if remapped || other.remapped {
+						collisions = append(collisions, DestinationCollision{From: from, To: to, Other: other.from})
+						continue
+					}
+					// the earlier file is overwritten
+					collisions = append(collisions, DestinationCollision{From: other.from, To: to, Other: from})
+				}
+				dests[to] = published{from, remapped}
+
 				// file is ok
 				out = append(out, NpmFileMoveOp{
-					From: path.Join(*fileMap.BasePath, f),
-					To:   f,
+					From: from,
+					To:   to,
 				})
 			}
 		}
 	}
 
-	return out
+	return out, collisions
 }
 
 // // AllFiles lists all files in the version directory.
@@ -227,58 +304,3 @@ type Asset struct {
 	Version string   `json:"version"`
 	Files   []string `json:"files"`
 }
-
-// A "stable" version is considered to be a version that contains no pre-releases.
-//
-// If no latest stable version is found (ex. all are non-semver),
-// we will pick the greatest one using string comparison.
-//
-// If there are no versions at all, a nil *string will be returned.
-func GetLatestStableVersion(versions []string) *string {
-	var latestStable *semver.Version
-	var anySemver *semver.Version
-	var greatestStr *string
-	for i := 0; i < len(versions); i++ {
-		version := versions[i]
-		s, err := semver.Parse(version)
-		if err == nil {
-			if len(s.Pre) == 0 {
-				// stable semver
-				if latestStable != nil {
-					if latestStable.LT(s) {
-						latestStable = &s
-					}
-				} else {
-					latestStable = &s
-				}
-			} else {
-				// not stable semver
-				if anySemver != nil {
-					if anySemver.LT(s) {
-						anySemver = &s
-					}
-				} else {
-					anySemver = &s
-				}
-			}
-		} else {
-			// not semver
-			if greatestStr != nil {
-				if *greatestStr < version {
-					greatestStr = &version
-				}
-			} else {
-				greatestStr = &version
-			}
-		}
-	}
-	if latestStable != nil {
-		s := latestStable.String()
-		return &s
-	}
-	if anySemver != nil {
-		s := anySemver.String()
-		return &s
-	}
-	return greatestStr
-}
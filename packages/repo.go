@@ -3,55 +3,201 @@ package packages
 import (
 	"archive/zip"
 	"context"
+	"crypto/sha1"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-const PACKAGES_ZIP = "https://github.com/cdnjs/packages/archive/refs/heads/master.zip"
+const (
+	PACKAGES_ZIP = "https://github.com/cdnjs/packages/archive/refs/heads/master.zip"
+	// zip of the packages repository at a particular commit
+	PACKAGES_ZIP_AT = "https://github.com/cdnjs/packages/archive/%s.zip"
+)
+
+var (
+	// Directory in which the packages zips and their ETags are kept
+	// across processes. If empty, zips are only cached in memory.
+	PACKAGES_CACHE_DIR = os.Getenv("PACKAGES_CACHE_DIR")
+
+	// ZipCacheTTL is how long the packages of a branch zip are used
+	// before checking if the zip has changed.
+	ZipCacheTTL = 5 * time.Minute
+
+	commitRegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
 
+// FetchPackages returns the packages of the default source.
+//
+// Deprecated: use DefaultSource().Packages instead.
 func FetchPackages() ([]*Package, error) {
-	zipfile, err := ioutil.TempFile("", "zip")
-	if err != nil {
-		return nil, errors.Wrap(err, "could not create temp file")
+	return DefaultSource().Packages(context.Background())
+}
+
+// GetRepoPackage returns the package with a particular
+// name from the default source.
+//
+// Deprecated: use DefaultSource().Package instead.
+func GetRepoPackage(name string) (*Package, error) {
+	return DefaultSource().Package(context.Background(), name)
+}
+
+// zipCacheEntry is the parsed packages of a zip.
+type zipCacheEntry struct {
+	etag      string
+	list      []*Package
+	checkedAt time.Time
+}
+
+var (
+	zipCacheMu sync.Mutex
+	zipCache   = make(map[string]*zipCacheEntry)
+)
+
+// Fetches and parses the packages zip at a URL, using the cached packages
+// if they are recent enough or the zip has not changed since, based on its
+// ETag. Zips of a commit are immutable and never fetched again once cached.
+func fetchPackagesZip(ctx context.Context, url string, immutable bool) ([]*Package, error) {
+	zipCacheMu.Lock()
+	defer zipCacheMu.Unlock()
+
+	entry, ok := zipCache[url]
+	if !ok {
+		entry = readDiskCache(ctx, url)
+		if entry != nil {
+			zipCache[url] = entry
+		}
+	}
+	if entry != nil && (immutable || time.Since(entry.checkedAt) < ZipCacheTTL) {
+		return entry.list, nil
 	}
-	defer os.Remove(zipfile.Name())
 
-	resp, err := http.Get(PACKAGES_ZIP)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create request")
+	}
+	if entry != nil && entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not fetch packages")
 	}
 	defer resp.Body.Close()
-	_, err = io.Copy(zipfile, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		log.Printf("packages zip %s not modified\n", url)
+		entry.checkedAt = time.Now()
+		return entry.list, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("could not fetch packages: %s returned %s", url, resp.Status)
+	}
+
+	zipfile, err := ioutil.TempFile("", "zip")
 	if err != nil {
+		return nil, errors.Wrap(err, "could not create temp file")
+	}
+	defer os.Remove(zipfile.Name())
+	defer zipfile.Close()
+
+	if _, err := io.Copy(zipfile, resp.Body); err != nil {
 		return nil, errors.Wrap(err, "could not download packages zip")
 	}
 
-	packages, err := inflatePackages(zipfile)
+	list, err := inflatePackages(ctx, zipfile)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not inflate packages")
 	}
-	return packages, nil
+
+	entry = &zipCacheEntry{
+		etag:      resp.Header.Get("ETag"),
+		list:      list,
+		checkedAt: time.Now(),
+	}
+	zipCache[url] = entry
+	writeDiskCache(url, entry.etag, zipfile.Name())
+	return list, nil
 }
 
-func GetRepoPackage(name string) (*Package, error) {
-	packages, err := FetchPackages()
+// Returns the path in the disk cache of the zip at a URL.
+func diskCachePath(url string) string {
+	return filepath.Join(PACKAGES_CACHE_DIR, fmt.Sprintf("%x", sha1.Sum([]byte(url))))
+}
+
+// Reads a zip from the disk cache, which needs to be revalidated
+// before being used unless it is immutable.
+func readDiskCache(ctx context.Context, url string) *zipCacheEntry {
+	if PACKAGES_CACHE_DIR == "" {
+		return nil
+	}
+
+	file := diskCachePath(url)
+	etag, err := ioutil.ReadFile(file + ".etag")
 	if err != nil {
-		return nil, errors.Wrap(err, "could not fetch packages")
+		return nil
 	}
-	for _, pkg := range packages {
-		if *pkg.Name == name {
-			return pkg, nil
+	zipfile, err := os.Open(file + ".zip")
+	if err != nil {
+		return nil
+	}
+	defer zipfile.Close()
+
+	list, err := inflatePackages(ctx, zipfile)
+	if err != nil {
+		log.Printf("ignoring cached packages zip %s: %s\n", zipfile.Name(), err)
+		return nil
+	}
+	return &zipCacheEntry{etag: string(etag), list: list}
+}
+
+// Writes a zip to the disk cache, ignoring failures.
+func writeDiskCache(url, etag, zipfile string) {
+	if PACKAGES_CACHE_DIR == "" {
+		return
+	}
+
+	file := diskCachePath(url)
+	bytes, err := ioutil.ReadFile(zipfile)
+	if err == nil {
+		err = os.MkdirAll(PACKAGES_CACHE_DIR, os.ModePerm)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(file+".zip", bytes, 0644)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(file+".etag", []byte(etag), 0644)
+	}
+	if err != nil {
+		log.Printf("could not cache packages zip %s: %s\n", url, err)
+	}
+}
+
+// Returns the path of a package file relative to the root of
+// the packages repository, or false if it isn't a package file.
+// Archives may contain a top-level directory (ex. `packages-master/`).
+func packageFilePath(name string) (string, bool) {
+	if !strings.HasPrefix(name, "packages/") {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) < 2 {
+			return "", false
 		}
+		name = parts[1]
 	}
-	return nil, errors.New("package config not found")
+	return name, strings.HasPrefix(name, "packages/") && strings.HasSuffix(name, ".json")
 }
 
-func inflatePackages(src *os.File) ([]*Package, error) {
+func inflatePackages(ctx context.Context, src *os.File) ([]*Package, error) {
 	var list []*Package
 
 	r, err := zip.OpenReader(src.Name())
@@ -60,17 +206,14 @@ func inflatePackages(src *os.File) ([]*Package, error) {
 	}
 	defer r.Close()
 
-	prefix := "packages-master/packages"
-	// FIXME: pass from root
-	ctx := context.Background()
-
 	for _, f := range r.File {
-		if strings.HasPrefix(f.Name, prefix) && strings.HasSuffix(f.Name, ".json") {
+		if _, ok := packageFilePath(f.Name); ok {
 			reader, err := f.Open()
 			if err != nil {
 				return nil, errors.Wrap(err, "could open file")
 			}
 			bytes, err := ioutil.ReadAll(reader)
+			reader.Close()
 			if err != nil {
 				return nil, errors.Wrap(err, "could not read file")
 			}
@@ -0,0 +1,218 @@
+package packages
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cdnjs/tools/util"
+)
+
+// The package schemas are generated from the struct tags of Package
+// so that the Go types and the schemas can't drift:
+//
+//   - `schema` marks a field as part of the schema, with a comma-separated list of options:
+//     `human` and `nonHuman` select the schemas a Package property belongs to
+//     (human-readable properties are part of both), `required` and `nonHumanRequired`
+//     make it required, and `minLength=N`, `minItems=N`, `uniqueItems` and `itemMinLength=N`
+//     are the usual JSON schema constraints.
+//   - `pattern` is the regular expression a string must match.
+//   - `description` documents a Package property.
+//
+// Nested structures are objects that disallow additional properties.
+
+type schemaKind int
+
+const (
+	humanReadable schemaKind = iota
+	nonHumanReadable
+)
+
+// schemaNode is a JSON object that keeps the order of its keys.
+type schemaNode []schemaEntry
+
+type schemaEntry struct {
+	key   string
+	value interface{}
+}
+
+func (n *schemaNode) set(key string, value interface{}) {
+	*n = append(*n, schemaEntry{key, value})
+}
+
+// MarshalJSON is used to satisfy the json.Marshaler interface.
+func (n schemaNode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range n {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(e.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// schemaOptions are the parsed `schema` tag of a field.
+type schemaOptions map[string]string
+
+func (o schemaOptions) has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+func (o schemaOptions) int(name string) (int, bool) {
+	v, ok := o[name]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(v)
+	util.Check(err)
+	return i, true
+}
+
+func parseSchemaTag(tag string) schemaOptions {
+	opts := make(schemaOptions)
+	for _, opt := range strings.Split(tag, ",") {
+		if opt == "" {
+			continue
+		}
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return opts
+}
+
+// Returns the JSON name of a field.
+func jsonName(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("json"), ",")[0]
+}
+
+func generateSchema(kind schemaKind) string {
+	properties := schemaNode{}
+	var required []string
+
+	t := reflect.TypeOf(Package{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("schema")
+		if !ok {
+			continue
+		}
+
+		opts := parseSchemaTag(tag)
+		if !opts.has("human") && !(kind == nonHumanReadable && opts.has("nonHuman")) {
+			continue
+		}
+		if (kind == humanReadable && opts.has("required")) ||
+			(kind == nonHumanReadable && opts.has("nonHumanRequired")) {
+			required = append(required, jsonName(f))
+		}
+		properties.set(jsonName(f), fieldSchema(f, opts))
+	}
+	sort.Strings(required)
+
+	root := schemaNode{}
+	root.set("$schema", "http://json-schema.org/draft-07/schema#")
+	root.set("type", "object")
+	root.set("properties", properties)
+	root.set("required", required)
+	root.set("additionalProperties", false)
+
+	bytes, err := json.MarshalIndent(root, "", "    ")
+	util.Check(err)
+	return string(bytes)
+}
+
+func fieldSchema(f reflect.StructField, opts schemaOptions) schemaNode {
+	n := schemaNode{}
+	if description, ok := f.Tag.Lookup("description"); ok {
+		n.set("description", description)
+	}
+
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		n.set("type", "string")
+		if min, ok := opts.int("minLength"); ok {
+			n.set("minLength", min)
+		}
+		if pattern, ok := f.Tag.Lookup("pattern"); ok {
+			n.set("pattern", pattern)
+		}
+	case reflect.Bool:
+		n.set("type", "boolean")
+	case reflect.Slice:
+		n.set("type", "array")
+		if min, ok := opts.int("minItems"); ok {
+			n.set("minItems", min)
+		}
+		if opts.has("uniqueItems") {
+			n.set("uniqueItems", true)
+		}
+		if t.Elem().Kind() == reflect.String {
+			items := schemaNode{}
+			items.set("type", "string")
+			if min, ok := opts.int("itemMinLength"); ok {
+				items.set("minLength", min)
+			}
+			n.set("items", items)
+		} else {
+			n.set("items", objectSchema(t.Elem()))
+		}
+	case reflect.Struct:
+		n = append(n, objectSchema(t)...)
+	default:
+		panic("unsupported schema type " + t.String())
+	}
+	return n
+}
+
+func objectSchema(t reflect.Type) schemaNode {
+	properties := schemaNode{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("schema")
+		if !ok {
+			continue
+		}
+
+		opts := parseSchemaTag(tag)
+		if opts.has("required") {
+			required = append(required, jsonName(f))
+		}
+		properties.set(jsonName(f), fieldSchema(f, opts))
+	}
+	sort.Strings(required)
+
+	n := schemaNode{}
+	n.set("type", "object")
+	n.set("properties", properties)
+	if len(required) > 0 {
+		n.set("required", required)
+	}
+	n.set("additionalProperties", false)
+	return n
+}
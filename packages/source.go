@@ -0,0 +1,194 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Local checkout of the cdnjs/packages repository to load packages from.
+	PACKAGES_DIR = os.Getenv("PACKAGES_DIR")
+	// Git ref of the local checkout to load packages from or,
+	// without a local checkout, commit SHA on GitHub.
+	PACKAGES_REF = os.Getenv("PACKAGES_REF")
+)
+
+// PackageSource loads the packages of the cdnjs/packages repository.
+type PackageSource interface {
+	// Packages returns all the packages.
+	Packages(ctx context.Context) ([]*Package, error)
+	// Package returns the package with a particular name.
+	Package(ctx context.Context, name string) (*Package, error)
+}
+
+// DefaultSource returns the source set by the PACKAGES_DIR and
+// PACKAGES_REF environment variables, defaulting to the GitHub
+// zip of the master branch.
+func DefaultSource() PackageSource {
+	switch {
+	case PACKAGES_DIR != "" && PACKAGES_REF != "":
+		return NewGitRefSource(PACKAGES_DIR, PACKAGES_REF)
+	case PACKAGES_DIR != "":
+		return NewDirSource(PACKAGES_DIR)
+	case PACKAGES_REF != "":
+		return NewGitHubSourceAt(PACKAGES_REF)
+	default:
+		return NewGitHubSource()
+	}
+}
+
+// NewGitHubSource returns a source loading the packages from the
+// zip of the master branch on GitHub.
+func NewGitHubSource() PackageSource {
+	return NewZipSource(PACKAGES_ZIP)
+}
+
+// NewGitHubSourceAt returns a source loading the packages from
+// the zip of a particular commit on GitHub.
+func NewGitHubSourceAt(commit string) PackageSource {
+	return &catalogue{reload: true, load: func(ctx context.Context) ([]*Package, error) {
+		if !commitRegex.MatchString(commit) {
+			return nil, errors.Errorf("invalid commit SHA `%s`", commit)
+		}
+		return fetchPackagesZip(ctx, fmt.Sprintf(PACKAGES_ZIP_AT, commit), true)
+	}}
+}
+
+// NewZipSource returns a source loading the packages from the zip
+// of a branch of the packages repository, such as PACKAGES_ZIP.
+// The zip is cached for ZipCacheTTL and then only fetched again
+// if its ETag has changed.
+func NewZipSource(url string) PackageSource {
+	return &catalogue{reload: true, load: func(ctx context.Context) ([]*Package, error) {
+		return fetchPackagesZip(ctx, url, false)
+	}}
+}
+
+// NewDirSource returns a source loading the packages from
+// a local checkout of the packages repository.
+func NewDirSource(dir string) PackageSource {
+	return &catalogue{load: func(ctx context.Context) ([]*Package, error) {
+		var list []*Package
+
+		root := filepath.Join(dir, "packages")
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			if _, ok := packageFilePath(filepath.ToSlash(rel)); !ok || info.IsDir() {
+				return nil
+			}
+
+			bytes, err := ioutil.ReadFile(p)
+			if err != nil {
+				return errors.Wrap(err, "could not read file")
+			}
+			pkg, err := ReadHumanJSONBytes(ctx, rel, bytes, false)
+			if err != nil {
+				return errors.Wrapf(err, "could not parse Package: %s", rel)
+			}
+			list = append(list, pkg)
+			return nil
+		})
+		return list, err
+	}}
+}
+
+// NewGitRefSource returns a source loading the packages from a git
+// ref (ex. a branch or a commit) of a local clone of the packages
+// repository, regardless of its working tree.
+func NewGitRefSource(repo, ref string) PackageSource {
+	return &catalogue{load: func(ctx context.Context) ([]*Package, error) {
+		zipfile, err := ioutil.TempFile("", "zip")
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create temp file")
+		}
+		defer os.Remove(zipfile.Name())
+		defer zipfile.Close()
+
+		cmd := exec.CommandContext(ctx, "git", "archive", "--format=zip", "-o", zipfile.Name(), ref, "packages")
+		cmd.Dir = repo
+		log.Printf("run %s from %s\n", cmd, repo)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, errors.Wrapf(err, "could not archive %s: %s", ref, out)
+		}
+		return inflatePackages(ctx, zipfile)
+	}}
+}
+
+// catalogue loads the packages of a source, and indexes them by name.
+// Local sources are loaded once. Zip sources are loaded on each call,
+// since fetchPackagesZip caches the packages until the zip changes.
+type catalogue struct {
+	load   func(ctx context.Context) ([]*Package, error)
+	reload bool
+
+	mu     sync.Mutex
+	loaded bool
+	list   []*Package
+	byName map[string]*Package
+}
+
+// Returns the packages and their index, loading them if needed.
+func (c *catalogue) index(ctx context.Context) ([]*Package, map[string]*Package, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loaded || c.reload {
+		list, err := c.load(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not load packages")
+		}
+		// the same cached list is only indexed once
+		if !c.loaded || !sameList(list, c.list) {
+			c.byName = make(map[string]*Package)
+			for _, pkg := range list {
+				if pkg.Name != nil {
+					c.byName[*pkg.Name] = pkg
+				}
+			}
+			c.list = list
+			c.loaded = true
+		}
+	}
+	return c.list, c.byName, nil
+}
+
+// Returns whether two lists are the same slice.
+func sameList(a, b []*Package) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// Packages is used to satisfy the PackageSource interface.
+func (c *catalogue) Packages(ctx context.Context) ([]*Package, error) {
+	list, _, err := c.index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// callers may reorder the list
+	return append([]*Package{}, list...), nil
+}
+
+// Package is used to satisfy the PackageSource interface.
+func (c *catalogue) Package(ctx context.Context, name string) (*Package, error) {
+	_, byName, err := c.index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, ok := byName[name]; ok {
+		return pkg, nil
+	}
+	return nil, errors.Errorf("package config not found: %s", name)
+}
@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var findingRegex = regexp.MustCompile(`file ("(?:[^"\\]|\\.)*") (not published, it looks sensitive|published, it may contain a secret): (.+)`)
+
+// Names of files holding credentials, lowercased.
+var sensitiveNames = map[string]string{
+	".env":             "environment file",
+	".npmrc":           "npm configuration",
+	".netrc":           "netrc credentials",
+	".htpasswd":        "htpasswd credentials",
+	".git-credentials": "git credentials",
+	"id_rsa":           "SSH private key",
+	"id_dsa":           "SSH private key",
+	"id_ecdsa":         "SSH private key",
+	"id_ed25519":       "SSH private key",
+}
+
+// Extensions of files holding keys or certificates, lowercased.
+var sensitiveExts = map[string]string{
+	".pem": "PEM file",
+	".key": "key file",
+	".p12": "PKCS #12 file",
+	".pfx": "PKCS #12 file",
+}
+
+// A secret found in the content of a file.
+type secretRule struct {
+	name  string
+	regex *regexp.Regexp
+	// whether a match is enough to leave the file out, otherwise it is
+	// only reported since the rule matches documentations and examples too
+	block bool
+	// matches which aren't secrets, ex. the examples of documentations,
+	// given the submatches of the regex
+	ignore func(match []string) bool
+}
+
+// The rules which block files come first, so that they take precedence.
+var secretRules = []secretRule{
+	{
+		// a whole key, since PEM libraries include the header in their code,
+		// with its lines separated by newlines or `\n` in strings
+		name:  "private key",
+		regex: regexp.MustCompile(`-----BEGIN ([A-Z ]*PRIVATE KEY(?: BLOCK)?)-----(?:\r?\n|\\n)(?:[A-Za-z-]+: [^\r\n\\]*(?:\r?\n|\\n))*(?:\r?\n|\\n)?((?:[A-Za-z0-9+/=]+(?:\r?\n|\\n))+)-----END ([A-Z ]*PRIVATE KEY(?: BLOCK)?)-----`),
+		block: true,
+		ignore: func(match []string) bool {
+			return match[1] != match[3] || !isKeyMaterial(match[2])
+		},
+	},
+	{
+		name:  "AWS access key",
+		regex: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+		ignore: func(match []string) bool {
+			return strings.HasSuffix(match[0], "EXAMPLE")
+		},
+	},
+	{
+		name:  "npm token",
+		regex: regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`),
+	},
+	{
+		// tokens read from the environment, ex. `${NPM_TOKEN}`, are fine
+		name:  "npm token",
+		regex: regexp.MustCompile(`_authToken\s*=\s*[^$\s]\S*`),
+	},
+}
+
+// The minimum length of the base64 body of a key, which is much
+// longer for any real key.
+const minKeyMaterial = 64
+
+// Returns whether the body of a PEM block is base64 key material.
+func isKeyMaterial(body string) bool {
+	body = strings.NewReplacer("\r", "", "\n", "", `\n`, "").Replace(body)
+	if len(body) < minKeyMaterial {
+		return false
+	}
+	_, err := base64.StdEncoding.DecodeString(body)
+	return err == nil
+}
+
+// Finding represents a file whose name or content looks sensitive.
+type Finding struct {
+	File   string
+	Reason string
+	// Blocked is set when the file isn't published, since its name or a
+	// whole private key in it is sensitive. Other findings are reported only.
+	Blocked bool
+}
+
+// String is used to satisfy the fmt.Stringer interface. It is the
+// message logged when a sensitive file is found.
+func (f Finding) String() string {
+	if f.Blocked {
+		return fmt.Sprintf("file %q not published, it looks sensitive: %s", f.File, f.Reason)
+	}
+	return fmt.Sprintf("file %q published, it may contain a secret: %s", f.File, f.Reason)
+}
+
+// Scan returns a finding if a file, named relative to the
+// package, looks sensitive from its name or its content.
+func Scan(name string, content []byte) *Finding {
+	base := strings.ToLower(path.Base(name))
+	if reason, ok := sensitiveNames[base]; ok {
+		return &Finding{name, reason, true}
+	}
+	if strings.HasPrefix(base, ".env.") {
+		return &Finding{name, sensitiveNames[".env"], true}
+	}
+	if reason, ok := sensitiveExts[path.Ext(base)]; ok {
+		return &Finding{name, reason, true}
+	}
+
+	for _, rule := range secretRules {
+		for _, match := range rule.regex.FindAllStringSubmatch(string(content), -1) {
+			if rule.ignore == nil || !rule.ignore(match) {
+				return &Finding{name, "contains " + rule.name, rule.block}
+			}
+		}
+	}
+	return nil
+}
+
+// ScanFile scans a file on disk, named relative to the package.
+func ScanFile(file, name string) (*Finding, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read file")
+	}
+	return Scan(name, content), nil
+}
+
+// ParseFindings finds the sensitive files reported in
+// logs, for instance the ones of the sandbox.
+func ParseFindings(logs string) []Finding {
+	var findings []Finding
+	for _, m := range findingRegex.FindAllStringSubmatch(logs, -1) {
+		file, err := strconv.Unquote(m[1])
+		if err != nil {
+			continue
+		}
+		findings = append(findings, Finding{
+			File:    file,
+			Reason:  m[3],
+			Blocked: strings.HasPrefix(m[2], "not"),
+		})
+	}
+	return findings
+}
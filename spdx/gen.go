@@ -0,0 +1,115 @@
+//go:build ignore
+// +build ignore
+
+// Generates licenses.go from the license and exception lists published
+// by SPDX, run with `go generate ./spdx`.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	licensesURL   = flag.String("licenses", "https://spdx.org/licenses/licenses.json", "URL or path of the SPDX license list")
+	exceptionsURL = flag.String("exceptions", "https://spdx.org/licenses/exceptions.json", "URL or path of the SPDX exception list")
+	output        = flag.String("o", "licenses.go", "file to generate")
+)
+
+type licenseList struct {
+	Version  string `json:"licenseListVersion"`
+	Licenses []struct {
+		ID            string `json:"licenseId"`
+		IsOsiApproved bool   `json:"isOsiApproved"`
+	} `json:"licenses"`
+}
+
+type exceptionList struct {
+	Version    string `json:"licenseListVersion"`
+	Exceptions []struct {
+		ID string `json:"licenseExceptionId"`
+	} `json:"exceptions"`
+}
+
+func main() {
+	flag.Parse()
+
+	var licenses licenseList
+	if err := readJSON(*licensesURL, &licenses); err != nil {
+		log.Fatalf("could not read licenses: %s", err)
+	}
+	var exceptions exceptionList
+	if err := readJSON(*exceptionsURL, &exceptions); err != nil {
+		log.Fatalf("could not read exceptions: %s", err)
+	}
+	if len(licenses.Licenses) == 0 || len(exceptions.Exceptions) == 0 {
+		log.Fatalf("empty license list %s or exception list %s", licenses.Version, exceptions.Version)
+	}
+
+	sort.Slice(licenses.Licenses, func(i, j int) bool {
+		return strings.ToLower(licenses.Licenses[i].ID) < strings.ToLower(licenses.Licenses[j].ID)
+	})
+	sort.Slice(exceptions.Exceptions, func(i, j int) bool {
+		return strings.ToLower(exceptions.Exceptions[i].ID) < strings.ToLower(exceptions.Exceptions[j].ID)
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen.go from the SPDX license list %s; DO NOT EDIT.\n\n", licenses.Version)
+	fmt.Fprint(&buf, "package spdx\n\n")
+	fmt.Fprint(&buf, "// Licenses of the SPDX license list, including the deprecated\n")
+	fmt.Fprint(&buf, "// identifiers, and whether they are approved by the OSI.\n")
+	fmt.Fprint(&buf, "var licenses = map[string]bool{\n")
+	for _, l := range licenses.Licenses {
+		fmt.Fprintf(&buf, "%q: %t,\n", l.ID, l.IsOsiApproved)
+	}
+	fmt.Fprint(&buf, "}\n\n")
+	fmt.Fprint(&buf, "// Exceptions of the SPDX exception list, used with `WITH`.\n")
+	fmt.Fprint(&buf, "var exceptions = []string{\n")
+	for _, e := range exceptions.Exceptions {
+		fmt.Fprintf(&buf, "%q,\n", e.ID)
+	}
+	fmt.Fprint(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("could not format source: %s", err)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatalf("could not write %s: %s", *output, err)
+	}
+	log.Printf("generated %s: %d licenses, %d exceptions\n", *output, len(licenses.Licenses), len(exceptions.Exceptions))
+}
+
+// Reads a JSON document from a URL or a local file.
+func readJSON(src string, v interface{}) error {
+	var b []byte
+	if strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://") {
+		resp, err := http.Get(src)
+		if err != nil {
+			return errors.Wrapf(err, "could not fetch %s", src)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("could not fetch %s: %s", src, resp.Status)
+		}
+		if b, err = ioutil.ReadAll(resp.Body); err != nil {
+			return errors.Wrapf(err, "could not read %s", src)
+		}
+	} else {
+		var err error
+		if b, err = ioutil.ReadFile(src); err != nil {
+			return errors.Wrapf(err, "could not read %s", src)
+		}
+	}
+	return errors.Wrapf(json.Unmarshal(b, v), "could not parse %s", src)
+}
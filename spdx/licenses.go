@@ -0,0 +1,146 @@
+package spdx
+
+// Licenses of the SPDX license list commonly used by libraries,
+// by identifier, and whether they are approved by the OSI. Run
+// `go generate ./spdx` to replace them with the whole SPDX lists.
+var licenses = map[string]bool{
+	"0BSD":                          true,
+	"AFL-1.1":                       true,
+	"AFL-1.2":                       true,
+	"AFL-2.0":                       true,
+	"AFL-2.1":                       true,
+	"AFL-3.0":                       true,
+	"AGPL-1.0":                      false,
+	"AGPL-1.0-only":                 false,
+	"AGPL-1.0-or-later":             false,
+	"AGPL-3.0":                      true,
+	"AGPL-3.0-only":                 true,
+	"AGPL-3.0-or-later":             true,
+	"Apache-1.0":                    false,
+	"Apache-1.1":                    true,
+	"Apache-2.0":                    true,
+	"APSL-2.0":                      true,
+	"Artistic-1.0":                  true,
+	"Artistic-2.0":                  true,
+	"Beerware":                      false,
+	"BlueOak-1.0.0":                 true,
+	"BSD-1-Clause":                  true,
+	"BSD-2-Clause":                  true,
+	"BSD-2-Clause-Patent":           true,
+	"BSD-3-Clause":                  true,
+	"BSD-3-Clause-Clear":            false,
+	"BSD-3-Clause-LBNL":             true,
+	"BSD-4-Clause":                  false,
+	"BSL-1.0":                       true,
+	"CAL-1.0":                       true,
+	"CC-BY-1.0":                     false,
+	"CC-BY-2.0":                     false,
+	"CC-BY-2.5":                     false,
+	"CC-BY-3.0":                     false,
+	"CC-BY-4.0":                     false,
+	"CC-BY-NC-3.0":                  false,
+	"CC-BY-NC-4.0":                  false,
+	"CC-BY-NC-ND-3.0":               false,
+	"CC-BY-NC-ND-4.0":               false,
+	"CC-BY-NC-SA-3.0":               false,
+	"CC-BY-NC-SA-4.0":               false,
+	"CC-BY-ND-3.0":                  false,
+	"CC-BY-ND-4.0":                  false,
+	"CC-BY-SA-2.0":                  false,
+	"CC-BY-SA-2.5":                  false,
+	"CC-BY-SA-3.0":                  false,
+	"CC-BY-SA-4.0":                  false,
+	"CC-PDDC":                       false,
+	"CC0-1.0":                       false,
+	"CDDL-1.0":                      true,
+	"CDDL-1.1":                      false,
+	"CECILL-2.1":                    true,
+	"CPAL-1.0":                      true,
+	"CPL-1.0":                       true,
+	"curl":                          false,
+	"ECL-2.0":                       true,
+	"EFL-2.0":                       true,
+	"EPL-1.0":                       true,
+	"EPL-2.0":                       true,
+	"EUPL-1.1":                      true,
+	"EUPL-1.2":                      true,
+	"GPL-1.0":                       false,
+	"GPL-1.0-only":                  false,
+	"GPL-1.0-or-later":              false,
+	"GPL-2.0":                       true,
+	"GPL-2.0-only":                  true,
+	"GPL-2.0-or-later":              true,
+	"GPL-3.0":                       true,
+	"GPL-3.0-only":                  true,
+	"GPL-3.0-or-later":              true,
+	"Hippocratic-2.1":               false,
+	"IJG":                           false,
+	"ISC":                           true,
+	"JSON":                          false,
+	"LGPL-2.0":                      true,
+	"LGPL-2.0-only":                 true,
+	"LGPL-2.0-or-later":             true,
+	"LGPL-2.1":                      true,
+	"LGPL-2.1-only":                 true,
+	"LGPL-2.1-or-later":             true,
+	"LGPL-3.0":                      true,
+	"LGPL-3.0-only":                 true,
+	"LGPL-3.0-or-later":             true,
+	"Libpng":                        false,
+	"libpng-2.0":                    false,
+	"LPPL-1.3c":                     true,
+	"MirOS":                         true,
+	"MIT":                           true,
+	"MIT-0":                         true,
+	"MIT-CMU":                       false,
+	"MPL-1.0":                       true,
+	"MPL-1.1":                       true,
+	"MPL-2.0":                       true,
+	"MPL-2.0-no-copyleft-exception": true,
+	"MS-PL":                         true,
+	"MS-RL":                         true,
+	"MulanPSL-2.0":                  true,
+	"NCSA":                          true,
+	"ODbL-1.0":                      false,
+	"OFL-1.0":                       false,
+	"OFL-1.1":                       true,
+	"OFL-1.1-no-RFN":                true,
+	"OFL-1.1-RFN":                   true,
+	"OpenSSL":                       false,
+	"OSL-3.0":                       true,
+	"PHP-3.0":                       true,
+	"PHP-3.01":                      true,
+	"PostgreSQL":                    true,
+	"Python-2.0":                    true,
+	"Ruby":                          false,
+	"Unicode-3.0":                   true,
+	"Unicode-DFS-2016":              true,
+	"Unlicense":                     true,
+	"UPL-1.0":                       true,
+	"Vim":                           false,
+	"W3C":                           true,
+	"WTFPL":                         false,
+	"X11":                           false,
+	"Zlib":                          true,
+	"ZPL-2.0":                       true,
+	"ZPL-2.1":                       true,
+}
+
+// Exceptions of the SPDX exception list, used with `WITH`.
+var exceptions = []string{
+	"Autoconf-exception-2.0",
+	"Autoconf-exception-3.0",
+	"Bison-exception-2.2",
+	"Classpath-exception-2.0",
+	"eCos-exception-2.0",
+	"FLTK-exception",
+	"Font-exception-2.0",
+	"GCC-exception-2.0",
+	"GCC-exception-3.1",
+	"Linux-syscall-note",
+	"LLVM-exception",
+	"OpenJDK-assembly-exception-1.0",
+	"Qt-LGPL-exception-1.1",
+	"u-boot-exception-2.0",
+	"WxWindows-exception-3.1",
+}
@@ -0,0 +1,253 @@
+package spdx
+
+//go:generate go run gen.go
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	tokenRegex      = regexp.MustCompile(`[()]|[^()\s]+`)
+	licenseRefRegex = regexp.MustCompile(`^(DocumentRef-[a-zA-Z0-9.-]+:)?LicenseRef-[a-zA-Z0-9.-]+$`)
+)
+
+// Lowercased identifiers, since they are matched case-insensitively.
+var (
+	licenseIDs   = make(map[string]string)
+	exceptionIDs = make(map[string]string)
+)
+
+func init() {
+	for id := range licenses {
+		licenseIDs[strings.ToLower(id)] = id
+	}
+	for _, id := range exceptions {
+		exceptionIDs[strings.ToLower(id)] = id
+	}
+}
+
+// Expression is a parsed SPDX license expression, ex. `(MIT OR Apache-2.0)`.
+type Expression struct {
+	// Operator is `AND` or `OR` for a compound expression, of its
+	// operands, and empty for a license.
+	Operator string
+	Operands []*Expression
+	// License is the identifier of a license, ex. `GPL-2.0+` or `LicenseRef-Custom`.
+	License   string
+	Exception string
+}
+
+// Parse parses an SPDX license expression, whose licenses and exceptions
+// must be on the SPDX lists, or references to other licenses.
+func Parse(s string) (*Expression, error) {
+	p := &parser{tokens: tokenRegex.FindAllString(s, -1)}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty license expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, errors.Errorf("unexpected `%s`", tok)
+	}
+	return e, nil
+}
+
+// String returns the canonical form of the expression, where the
+// operands are sorted and compound operands are parenthesized.
+func (e *Expression) String() string {
+	if e.Operator == "" {
+		if e.Exception != "" {
+			return e.License + " WITH " + e.Exception
+		}
+		return e.License
+	}
+
+	operands := make([]string, 0, len(e.Operands))
+	for _, o := range e.Operands {
+		if o.Operator != "" {
+			operands = append(operands, "("+o.String()+")")
+		} else {
+			operands = append(operands, o.String())
+		}
+	}
+	sort.Strings(operands)
+	return strings.Join(operands, " "+e.Operator+" ")
+}
+
+// Equal returns whether two expressions are the same, regardless
+// of the order of the operands and of the case of identifiers.
+func (e *Expression) Equal(o *Expression) bool {
+	return e.String() == o.String()
+}
+
+// Licenses returns the identifiers of the licenses of the expression.
+func (e *Expression) Licenses() []string {
+	if e.Operator == "" {
+		return []string{e.License}
+	}
+	var ids []string
+	for _, o := range e.Operands {
+		ids = append(ids, o.Licenses()...)
+	}
+	return ids
+}
+
+// OSIApproved returns whether the expression can be satisfied with licenses
+// approved by the OSI only, ex. `MIT OR CC-BY-4.0` can with `MIT`.
+func (e *Expression) OSIApproved() bool {
+	switch e.Operator {
+	case "OR":
+		for _, o := range e.Operands {
+			if o.OSIApproved() {
+				return true
+			}
+		}
+		return false
+	case "AND":
+		for _, o := range e.Operands {
+			if !o.OSIApproved() {
+				return false
+			}
+		}
+		return true
+	default:
+		return licenses[strings.TrimSuffix(e.License, "+")]
+	}
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (string, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return "", errors.New("unexpected end of expression")
+	}
+	p.pos++
+	return tok, nil
+}
+
+// Consumes the next token if it is an operator, which is
+// either uppercase or lowercase.
+func (p *parser) accept(operator string) bool {
+	if tok, ok := p.peek(); ok && isOperator(tok, operator) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func isOperator(tok, operator string) bool {
+	return tok == operator || tok == strings.ToLower(operator)
+}
+
+// OR has a lower precedence than AND.
+func (p *parser) parseOr() (*Expression, error) {
+	e, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		o, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		e = combine("OR", e, o)
+	}
+	return e, nil
+}
+
+func (p *parser) parseAnd() (*Expression, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		o, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		e = combine("AND", e, o)
+	}
+	return e, nil
+}
+
+func (p *parser) parsePrimary() (*Expression, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok == "(" {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, err := p.next(); err != nil || tok != ")" {
+			return nil, errors.New("missing `)`")
+		}
+		return e, nil
+	}
+	if tok == ")" || isOperator(tok, "AND") || isOperator(tok, "OR") || isOperator(tok, "WITH") {
+		return nil, errors.Errorf("unexpected `%s`", tok)
+	}
+
+	license, err := canonicalLicense(tok)
+	if err != nil {
+		return nil, err
+	}
+	e := &Expression{License: license}
+	if p.accept("WITH") {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		exception, ok := exceptionIDs[strings.ToLower(tok)]
+		if !ok {
+			return nil, errors.Errorf("unknown license exception `%s`", tok)
+		}
+		e.Exception = exception
+	}
+	return e, nil
+}
+
+// Returns the identifier of a license as on the SPDX list.
+func canonicalLicense(tok string) (string, error) {
+	id := strings.TrimSuffix(tok, "+")
+	suffix := tok[len(id):]
+	if licenseRefRegex.MatchString(id) {
+		return tok, nil
+	}
+	if canonical, ok := licenseIDs[strings.ToLower(id)]; ok {
+		return canonical + suffix, nil
+	}
+	return "", errors.Errorf("unknown license `%s`", tok)
+}
+
+// Combines two expressions with an operator, flattening
+// the operands which use the same operator.
+func combine(operator string, a, b *Expression) *Expression {
+	e := &Expression{Operator: operator}
+	for _, o := range []*Expression{a, b} {
+		if o.Operator == operator {
+			e.Operands = append(e.Operands, o.Operands...)
+		} else {
+			e.Operands = append(e.Operands, o)
+		}
+	}
+	return e
+}
@@ -19,31 +19,24 @@ func createFakeBotPath() string {
 		panic(err)
 	}
 
-	// create fake glob that will return all the files all the time
-	{
-		dir := path.Join(botpath, "glob")
-		if err := os.Mkdir(dir, os.ModePerm); err != nil {
-			panic(err)
-		}
-
-		content := []byte(`#!/usr/bin/env node
-			const fs = require('fs');
-			fs.readdirSync(process.cwd()).forEach(file => {
-				console.log(file);
-			});
-		`)
-		err := ioutil.WriteFile(path.Join(dir, "index.js"), content, 0777)
-		if err != nil {
-			panic(err)
-		}
-
-	}
-
 	return botpath
 }
 
 // start a local proxy server and run the checker binary
 func runChecker(fakeBotPath string, proxy string, validatePath bool, args ...string) string {
+	out, _ := checkerCmd(fakeBotPath, proxy, validatePath, args...).CombinedOutput()
+
+	return string(out)
+}
+
+// Runs the checker like runChecker, without the logs written to STDERR.
+func runCheckerStdout(fakeBotPath string, proxy string, validatePath bool, args ...string) string {
+	out, _ := checkerCmd(fakeBotPath, proxy, validatePath, args...).Output()
+
+	return string(out)
+}
+
+func checkerCmd(fakeBotPath string, proxy string, validatePath bool, args ...string) *exec.Cmd {
 	// used to avoid validating the package's path
 	if !validatePath {
 		args = append([]string{"-no-path-validation"}, args...)
@@ -53,17 +46,24 @@ func runChecker(fakeBotPath string, proxy string, validatePath bool, args ...str
 	cmd.Env = append(os.Environ(),
 		"HTTP_PROXY="+proxy,
 		"BOT_BASE_PATH="+fakeBotPath,
+		"NPM_REGISTRY_URL=http://registry.npmjs.org",
+		"NPM_DOWNLOADS_URL=http://api.npmjs.org/downloads",
 	)
-
-	out, _ := cmd.CombinedOutput()
-
-	return string(out)
+	return cmd
 }
 
 func ciError(file, err string) string {
-	return fmt.Sprintf("::error file=%s,line=1,col=1::%s\n", file, err)
+	return ciErrorAt(file, 1, 1, err)
+}
+
+func ciErrorAt(file string, line, col int, err string) string {
+	return fmt.Sprintf("::error file=%s,line=%d,col=%d::%s\n", file, line, col, err)
 }
 
 func ciWarn(file, err string) string {
-	return fmt.Sprintf("::warning file=%s,line=1,col=1::%s\n", file, err)
+	return ciWarnAt(file, 1, 1, err)
+}
+
+func ciWarnAt(file string, line, col int, err string) string {
+	return fmt.Sprintf("::warning file=%s,line=%d,col=%d::%s\n", file, line, col, err)
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckerDiffInvalidPackages(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	oldFile := path.Join(fakeBotPath, "old.json")
+	newFile := path.Join(fakeBotPath, "packages", "packages", "a", "a-happy-tyler.json")
+	assert.Nil(t, os.MkdirAll(path.Dir(newFile), os.ModePerm))
+
+	// the new package is linted
+	assert.Nil(t, ioutil.WriteFile(oldFile, []byte(formattedPkg), 0644))
+	assert.Nil(t, ioutil.WriteFile(newFile, []byte(`{"name": "a-happy-tyler"}`), 0644))
+
+	out := runChecker(fakeBotPath, "", false, "diff", oldFile, newFile)
+	assert.Contains(t, out, ciError(newFile, "(root): autoupdate is required"))
+
+	// while the old package needs to be valid
+	assert.Nil(t, ioutil.WriteFile(oldFile, []byte(`{"name": "a-happy-tyler"}`), 0644))
+	assert.Nil(t, ioutil.WriteFile(newFile, []byte(formattedPkg), 0644))
+
+	out = runChecker(fakeBotPath, "", false, "diff", oldFile, newFile)
+	assert.Contains(t, out, "failed to diff package: invalid old package")
+
+	out = runChecker(fakeBotPath, "", false, "diff", newFile)
+	assert.Contains(t, out, "usage: checker diff [-versions N] <old package> <new package>")
+}
+
+// Returns a package updated from npm, publishing files.
+func npmPkg(target, files string) string {
+	return fmt.Sprintf(`{
+  "name": "a-happy-tyler",
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": ["tyler"],
+  "license": "MIT",
+  "repository": {"type": "git", "url": "https://github.com/tc80/a-happy-tyler.git"},
+  "filename": "b.js",
+  "autoupdate": {
+    "source": "npm",
+    "target": "%s",
+    "fileMap": [{"basePath": "", "files": [%s]}]
+  }
+}`, target, files)
+}
+
+func TestCheckerDiffNoSandbox(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	httpTestProxy := "localhost:8666"
+	oldFile := path.Join(fakeBotPath, "old.json")
+	newFile := path.Join(fakeBotPath, "new.json")
+
+	assert.Nil(t, ioutil.WriteFile(oldFile, []byte(npmPkg(jsFilesPkg, `"a.js"`)), 0644))
+	assert.Nil(t, ioutil.WriteFile(newFile, []byte(npmPkg(jsFilesPkg, `"*.js"`)), 0644))
+
+	testproxy := &http.Server{
+		Addr:    httpTestProxy,
+		Handler: http.Handler(http.HandlerFunc(fakeNpmHandlerShowFiles)),
+	}
+	ln, err := net.Listen("tcp", httpTestProxy)
+	assert.Nil(t, err)
+
+	go func() {
+		if err := testproxy.Serve(ln); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	out := runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "diff", oldFile, newFile)
+	assert.Equal(t, `
+1 last version(s):
+- 0.0.2: 1 file(s) added, 0 file(s) removed
+  + b.js
+  filename `+"`b.js`"+` is now published :heavy_check_mark:
+`, out)
+
+	assert.Nil(t, testproxy.Shutdown(context.Background()))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const unformattedPkg = `{
+    "name": "a-happy-tyler",
+    "description": "Tyler is happy. Be like Tyler.",
+    "keywords": ["tyler", "happy", "tyler"],
+    "authors": [{"name": "Tyler Caslin"}],
+    "license": "MIT",
+    "repository": {"type": "git", "url": "git@github.com:tc80/a-happy-tyler.git"},
+    "autoupdate": {
+        "source": "npm",
+        "target": "a-happy-tyler",
+        "fileMap": [{"basePath": "", "files": ["*"]}]
+    }
+}`
+
+const formattedPkg = `{
+  "authors": [
+    {
+      "name": "Tyler Caslin"
+    }
+  ],
+  "autoupdate": {
+    "source": "npm",
+    "target": "a-happy-tyler",
+    "fileMap": [
+      {
+        "basePath": "",
+        "files": [
+          "*"
+        ]
+      }
+    ]
+  },
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": [
+    "happy",
+    "tyler"
+  ],
+  "license": "MIT",
+  "name": "a-happy-tyler",
+  "repository": {
+    "type": "git",
+    "url": "https://github.com/tc80/a-happy-tyler.git"
+  }
+}
+`
+
+func TestCheckerFmt(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	file := path.Join(fakeBotPath, "packages", "packages", "a", "a-happy-tyler.json")
+	assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(file, []byte(unformattedPkg), 0644))
+
+	// check mode reports the file without rewriting it
+	out := runChecker(fakeBotPath, "", true, "fmt", "-check", file)
+	assert.Equal(t, ciError(file, "package is not formatted, run `checker fmt`"), out)
+
+	content, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, unformattedPkg, string(content))
+
+	out = runChecker(fakeBotPath, "", true, "fmt", file)
+	assert.Contains(t, out, file+" formatted")
+
+	content, err = ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, formattedPkg, string(content))
+
+	out = runChecker(fakeBotPath, "", true, "fmt", "--check", file)
+	assert.Equal(t, "", out)
+
+	// invalid files are reported and left untouched
+	assert.Nil(t, ioutil.WriteFile(file, []byte(`{"name": "a-happy-tyler"}`), 0644))
+	out = runChecker(fakeBotPath, "", true, "fmt", file)
+	assert.Contains(t, out, ciError(file, "(root): autoupdate is required"))
+}
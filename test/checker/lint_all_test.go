@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a valid package updated from a git target.
+func gitPkg(name, target string) string {
+	return fmt.Sprintf(`{
+  "name": "%s",
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": ["tyler"],
+  "license": "MIT",
+  "repository": {"type": "git", "url": "https://github.com/%s.git"},
+  "filename": "happy.js",
+  "autoupdate": {
+    "source": "git",
+    "target": "%s",
+    "fileMap": [{"basePath": "", "files": ["*"]}]
+  }
+}`, name, popularRepo, target)
+}
+
+// Returns a valid package updated from an npm target.
+func namedNpmPkg(name, target string) string {
+	return fmt.Sprintf(`{
+  "name": "%s",
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": ["tyler"],
+  "license": "MIT",
+  "repository": {"type": "git", "url": "https://github.com/%s.git"},
+  "filename": "happy.js",
+  "autoupdate": {
+    "source": "npm",
+    "target": "%s",
+    "fileMap": [{"basePath": "", "files": ["*"]}]
+  }
+}`, name, popularRepo, target)
+}
+
+func TestCheckerLintAll(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	httpTestProxy := "localhost:8667"
+	checkout := path.Join(fakeBotPath, "packages")
+
+	files := map[string]string{
+		"packages/a/a-happy-tyler.json": gitPkg("a-happy-tyler", "https://github.com/tc80/a-happy-tyler.git"),
+		"packages/a/A-Happy-Tyler.json": gitPkg("A-Happy-Tyler", "https://github.com/tc80/a-sad-tyler.git"),
+		"packages/h/happy.json":         gitPkg("a-happy-tyler", "https://github.com/tc80/happy.git"),
+		"packages/s/sad.json":           gitPkg("sad", "git@github.com:tc80/Happy.git"),
+		"packages/x/tyler.json":         gitPkg("tyler", "https://github.com/tc80/tyler.git"),
+		"packages/b/broken.json":        namedNpmPkg("broken", brokenPkg),
+	}
+	for file, content := range files {
+		file = path.Join(checkout, file)
+		assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+		assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+
+	testproxy := &http.Server{
+		Addr:    httpTestProxy,
+		Handler: http.Handler(http.HandlerFunc(fakeNpmGitHubHandlerLint)),
+	}
+
+	go func() {
+		if err := testproxy.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	out := runChecker(fakeBotPath, httpTestProxy, true, "lint-all", checkout)
+
+	// a package which can't be linted doesn't stop the others
+	expected := []string{
+		ciError("packages/b/broken.json", "failed to lint: could not check npm package: could not fetch http://registry.npmjs.org/broken: 500 Internal Server Error"),
+		ciError("packages/x/tyler.json", "package `tyler` must go into `t` dir, not `x` dir"),
+		ciErrorAt("packages/a/a-happy-tyler.json", 2, 3, "name `a-happy-tyler` is also used by `packages/h/happy.json`"),
+		ciErrorAt("packages/h/happy.json", 2, 3, "name `a-happy-tyler` is also used by `packages/a/a-happy-tyler.json`"),
+		ciErrorAt("packages/a/A-Happy-Tyler.json", 2, 3, "name `A-Happy-Tyler` only differs in case from the name of `packages/a/a-happy-tyler.json`, `packages/h/happy.json`"),
+		ciErrorAt("packages/a/a-happy-tyler.json", 2, 3, "name `a-happy-tyler` only differs in case from the name of `packages/a/A-Happy-Tyler.json`"),
+		ciErrorAt("packages/h/happy.json", 2, 3, "name `a-happy-tyler` only differs in case from the name of `packages/a/A-Happy-Tyler.json`"),
+		ciWarnAt("packages/h/happy.json", 10, 5, "git repository `https://github.com/tc80/happy.git` is also the autoupdate target of `packages/s/sad.json`"),
+		ciWarnAt("packages/s/sad.json", 10, 5, "git repository `git@github.com:tc80/Happy.git` is also the autoupdate target of `packages/h/happy.json`"),
+		"\n6 package(s): 7 error(s), 2 warning(s), 0 suppressed\n",
+		"| duplicate-git-repo | warning | 2 |\n",
+		"| duplicate-name | error | 2 |\n",
+		"| lint-failed | error | 1 |\n",
+		"| name-collision | error | 3 |\n",
+		"| wrong-directory | error | 1 |\n",
+	}
+	for _, text := range expected {
+		assert.Contains(t, out, text)
+	}
+
+	assert.Nil(t, testproxy.Shutdown(context.Background()))
+}
@@ -17,36 +17,60 @@ type LintTestCase struct {
 	name         string
 	input        string
 	expected     []string
+	unexpected   []string
+	exceptions   string
 	file         *string
 	validatePath bool
 }
 
 const (
-	unpopularPkg   = "unpopular"
-	nonexistentPkg = "nonexistent"
-	normalPkg      = "normal"
-	unpopularRepo  = "user/unpopularRepo"
-	popularRepo    = "user/popularRepo"
+	unpopularPkg    = "unpopular"
+	nonexistentPkg  = "nonexistent"
+	brokenPkg       = "broken"
+	normalPkg       = "normal"
+	unpopularRepo   = "user/unpopularRepo"
+	popularRepo     = "user/popularRepo"
+	limitedRepo     = "user/limitedRepo"
+	licensedRepo    = "user/licensedRepo"
+	scopedNormalPkg = "@scope/" + normalPkg
 )
 
 // fakes the npm api and GitHub api for testing purposes
 func fakeNpmGitHubHandlerLint(w http.ResponseWriter, r *http.Request) {
+	// the slash of scoped packages is encoded, like npm expects
+	if strings.Contains(r.URL.Path, "/@scope/") && !strings.Contains(r.URL.RawPath, "/@scope%2F") {
+		w.WriteHeader(404)
+		fmt.Fprint(w, `{"error":"Not found"}`)
+		return
+	}
 	switch r.Host + r.URL.Path {
 	case "registry.npmjs.org/" + nonexistentPkg:
 		{
 			w.WriteHeader(404)
 			fmt.Fprint(w, `{"error":"Not found"}`)
 		}
+	case "registry.npmjs.org/" + brokenPkg:
+		{
+			w.WriteHeader(500)
+			fmt.Fprint(w, `{"error":"Internal server error"}`)
+		}
 	case "registry.npmjs.org/" + unpopularPkg:
-	case "registry.npmjs.org/" + normalPkg:
 		{
 			fmt.Fprint(w, `{}`)
 		}
+	case "registry.npmjs.org/" + normalPkg, "registry.npmjs.org/" + scopedNormalPkg:
+		{
+			fmt.Fprint(w, `{"dist-tags":{"latest":"1.0.0"},"versions":{"0.1.0":{},"1.0.0":{}}}`)
+		}
+	case "registry.npmjs.org/" + normalPkg + "/latest", "registry.npmjs.org/" + scopedNormalPkg + "/latest":
+		{
+			fmt.Fprint(w, `{"version":"1.0.0","license":"ISC"}`)
+		}
 	case "api.npmjs.org/downloads/point/last-month/" + unpopularPkg:
 		{
 			fmt.Fprintf(w, `{"downloads":3,"start":"2020-05-28","end":"2020-06-26","package":"%s"}`, unpopularPkg)
 		}
-	case "api.npmjs.org/downloads/point/last-month/" + normalPkg:
+	case "api.npmjs.org/downloads/point/last-month/" + normalPkg, "api.npmjs.org/downloads/point/last-month/" + scopedNormalPkg:
 		{
 			fmt.Fprintf(w, `{"downloads":31789789,"start":"2020-05-28","end":"2020-06-26","package":"%s"}`, normalPkg)
 		}
@@ -58,11 +82,39 @@ func fakeNpmGitHubHandlerLint(w http.ResponseWriter, r *http.Request) {
 		{
 			fmt.Fprintf(w, `{"stargazers_count": 500}`)
 		}
+	case "api.github.com/repos/" + licensedRepo:
+		{
+			fmt.Fprintf(w, `{"stargazers_count": 500, "license": {"key": "apache-2.0", "spdx_id": "Apache-2.0"}}`)
+		}
+	case "api.github.com/repos/" + limitedRepo:
+		{
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1600000000")
+			w.WriteHeader(403)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+		}
 	default:
 		panic(fmt.Sprintf("unknown path: %s", r.Host+r.URL.Path))
 	}
 }
 
+// Returns a valid package with a license, updated from a target.
+func licensedPkg(license, repo, source, target string) string {
+	return fmt.Sprintf(`{
+  "name": "a-happy-tyler",
+  "license": "%s",
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": ["tyler"],
+  "repository": {"type": "git", "url": "https://github.com/%s.git"},
+  "filename": "happy.js",
+  "autoupdate": {
+    "source": "%s",
+    "target": "%s",
+    "fileMap": [{"basePath": "", "files": ["*"]}]
+  }
+}`, license, repo, source, target)
+}
+
 func TestCheckerLint(t *testing.T) {
 	fakeBotPath := createFakeBotPath()
 	defer os.RemoveAll(fakeBotPath)
@@ -165,7 +217,7 @@ func TestCheckerLint(t *testing.T) {
 		        ]
 		    }
 		}`,
-			expected: []string{ciError(file, "(root): Additional property version is not allowed")},
+			expected: []string{ciErrorAt(file, 2, 4, "(root): Additional property version is not allowed")},
 		},
 
 		{
@@ -206,6 +258,172 @@ func TestCheckerLint(t *testing.T) {
 			expected: []string{ciWarn(file, "filename is missing")},
 		},
 
+		{
+			name: "warn if the GitHub rate limit is hit",
+			input: `{
+		    "name": "a-happy-tyler",
+		    "description": "Tyler is happy. Be like Tyler.",
+		    "keywords": [
+		        "tyler",
+		        "happy"
+		    ],
+		    "license": "MIT",
+		    "repository": {
+		        "type": "git",
+		        "url": "https://github.com/` + limitedRepo + `.git"
+		    },
+		    "filename": "happy.js",
+		    "autoupdate": {
+		        "source": "git",
+		        "target": "https://github.com/` + limitedRepo + `.git",
+		        "fileMap": [
+		            {
+		                "basePath": "src",
+		                "files": [
+		                    "*"
+		                ]
+		            }
+		        ]
+		    }
+		}`,
+			expected: []string{ciWarn(file, "check skipped: rate limit hit for http://api.github.com/repos/"+limitedRepo+", resets at 2020-09-13T12:26:40Z")},
+		},
+
+		{
+			name: "suppressed missing filename",
+			input: `{
+		    "name": "a-happy-tyler",
+		    "description": "Tyler is happy. Be like Tyler.",
+		    "keywords": [
+		        "tyler",
+		        "happy"
+		    ],
+		    "authors": [
+		        {
+		            "name": "Tyler Caslin",
+		            "email": "tylercaslin47@gmail.com",
+		            "url": "https://github.com/tc80"
+		        }
+		    ],
+		    "license": "MIT",
+		    "repository": {
+		        "type": "git",
+		        "url": "https://github.com/` + popularRepo + `.git"
+		    },
+		    "homepage": "https://github.com/tc80",
+		    "autoupdate": {
+		        "source": "git",
+		        "target": "https://github.com/` + popularRepo + `.git",
+		        "fileMap": [
+		            {
+		                "basePath": "src",
+		                "files": [
+		                    "*"
+		                ]
+		            }
+		        ]
+		    },
+		    "suppressions": [
+		        {
+		            "code": "missing-filename",
+		            "justification": "The library is a collection of plugins."
+		        }
+		    ]
+		}`,
+			expected:   []string{file + ": missing-filename suppressed: The library is a collection of plugins."},
+			unexpected: []string{ciWarn(file, "filename is missing")},
+		},
+
+		{
+			name: "missing filename suppressed by the exceptions file",
+			input: `{
+		    "name": "a-happy-tyler",
+		    "description": "Tyler is happy. Be like Tyler.",
+		    "keywords": [
+		        "tyler",
+		        "happy"
+		    ],
+		    "authors": [
+		        {
+		            "name": "Tyler Caslin",
+		            "email": "tylercaslin47@gmail.com",
+		            "url": "https://github.com/tc80"
+		        }
+		    ],
+		    "license": "MIT",
+		    "repository": {
+		        "type": "git",
+		        "url": "https://github.com/` + popularRepo + `.git"
+		    },
+		    "homepage": "https://github.com/tc80",
+		    "autoupdate": {
+		        "source": "git",
+		        "target": "https://github.com/` + popularRepo + `.git",
+		        "fileMap": [
+		            {
+		                "basePath": "src",
+		                "files": [
+		                    "*"
+		                ]
+		            }
+		        ]
+		    }
+		}`,
+			exceptions: `{"a-happy-tyler": [{"code": "missing-filename", "justification": "The library is a collection of plugins."}]}`,
+			expected:   []string{file + ": missing-filename suppressed: The library is a collection of plugins."},
+			unexpected: []string{ciWarn(file, "filename is missing")},
+		},
+
+		{
+			name: "errors can't be suppressed",
+			input: `{
+		    "name": "a-happy-tyler",
+		    "description": "Tyler is happy. Be like Tyler.",
+		    "keywords": [
+		        "tyler",
+		        "happy"
+		    ],
+		    "authors": [
+		        {
+		            "name": "Tyler Caslin",
+		            "email": "tylercaslin47@gmail.com",
+		            "url": "https://github.com/tc80"
+		        }
+		    ],
+		    "license": "MIT",
+		    "repository": {
+		        "type": "git",
+		        "url": "https://github.com/` + popularRepo + `.git"
+		    },
+		    "homepage": "https://github.com/tc80",
+		    "autoupdate": {
+		        "source": "git",
+		        "target": "https://github.com/` + popularRepo + `.git",
+		        "fileMap": [
+		            {
+		                "basePath": "src",
+		                "files": [
+		                    "*"
+		                ]
+		            }
+		        ]
+		    },
+		    "suppressions": [
+		        {
+		            "code": "missing-filename",
+		            "justification": "The library is a collection of plugins."
+		        },
+		        {
+		            "code": "npm-not-found",
+		            "justification": "The library is a collection of plugins."
+		        }
+		    ]
+		}`,
+			expected: []string{
+				ciErrorAt(file, 39, 15, "`npm-not-found` is an error, which can't be suppressed"),
+			},
+		},
+
 		{
 			name: "unknown autoupdate source",
 			input: `{
@@ -242,7 +460,7 @@ func TestCheckerLint(t *testing.T) {
 		        ]
 		    }
 		}`,
-			expected: []string{ciError(file, "autoupdate.source: Does not match pattern '"+autoupdateSourceRegex+"'")},
+			expected: []string{ciErrorAt(file, 23, 11, "autoupdate.source: Does not match pattern '"+autoupdateSourceRegex+"'")},
 		},
 
 		{
@@ -365,6 +583,113 @@ func TestCheckerLint(t *testing.T) {
 			expected: []string{ciWarn(file, "stars on GitHub is under 200")},
 		},
 
+		{
+			name: "error when invalid version range",
+			input: `{
+		    "name": "a-happy-tyler",
+		    "description": "Tyler is happy. Be like Tyler.",
+		    "keywords": [
+		        "tyler",
+		        "happy"
+		    ],
+		    "license": "MIT",
+		    "repository": {
+		        "type": "git",
+		        "url": "https://github.com/` + popularRepo + `.git"
+		    },
+		    "filename": "happy.js",
+		    "autoupdate": {
+		        "source": "git",
+		        "target": "https://github.com/` + popularRepo + `.git",
+		        "versionRange": ">=2.0.0 <four",
+		        "fileMap": [
+		            {
+		                "basePath": "src",
+		                "files": [
+		                    "*"
+		                ]
+		            }
+		        ]
+		    }
+		}`,
+			expected: []string{ciError(file, "invalid range `>=2.0.0 <four`: invalid comparator `<four`")},
+		},
+
+		{
+			name:     "warn when the license isn't a valid SPDX expression",
+			input:    licensedPkg("MIT OR", popularRepo, "git", "https://github.com/"+popularRepo+".git"),
+			expected: []string{ciWarnAt(file, 3, 3, "license `MIT OR` isn't a valid SPDX expression: unexpected end of expression")},
+		},
+
+		{
+			name:     "warn when the license isn't on the SPDX list",
+			input:    licensedPkg("MIT/X11", popularRepo, "git", "https://github.com/"+popularRepo+".git"),
+			expected: []string{ciWarnAt(file, 3, 3, "license `MIT/X11` isn't a valid SPDX expression: unknown license `MIT/X11`")},
+		},
+
+		{
+			name:     "warn when the license isn't approved by the OSI",
+			input:    licensedPkg("CC-BY-4.0", popularRepo, "git", "https://github.com/"+popularRepo+".git"),
+			expected: []string{ciWarnAt(file, 3, 3, "license `CC-BY-4.0` isn't approved by the OSI")},
+		},
+
+		{
+			name:       "licenses approved by the OSI can be chosen",
+			input:      licensedPkg("(MIT OR CC-BY-4.0)", popularRepo, "git", "https://github.com/"+popularRepo+".git"),
+			unexpected: []string{"isn't approved by the OSI"},
+		},
+
+		{
+			name:     "warn when the license doesn't match the latest version on npm",
+			input:    licensedPkg("MIT", popularRepo, "npm", normalPkg),
+			expected: []string{ciWarnAt(file, 3, 3, "license `MIT` doesn't match `ISC` declared by the latest version on npm")},
+		},
+
+		{
+			name:       "licenses are compared case-insensitively",
+			input:      licensedPkg("isc", popularRepo, "npm", normalPkg),
+			unexpected: []string{"declared by the latest version on npm"},
+		},
+
+		{
+			name:     "warn when the license doesn't include the one of the GitHub repository",
+			input:    licensedPkg("MIT", licensedRepo, "git", "https://github.com/"+licensedRepo+".git"),
+			expected: []string{ciWarnAt(file, 3, 3, "license `MIT` doesn't include `Apache-2.0` detected in the GitHub repository")},
+		},
+
+		{
+			name:       "the license of the GitHub repository can be one of the licenses",
+			input:      licensedPkg("(MIT OR Apache-2.0)", licensedRepo, "git", "https://github.com/"+licensedRepo+".git"),
+			unexpected: []string{"detected in the GitHub repository"},
+		},
+
+		{
+			name:       "scoped npm packages are looked up",
+			input:      strings.Replace(licensedPkg("ISC", popularRepo, "npm", scopedNormalPkg), "a-happy-tyler", "scope-normal", 1),
+			unexpected: []string{"::error", "::warning"},
+		},
+
+		{
+			name:     "suggest a library name for a scoped npm package",
+			input:    strings.Replace(licensedPkg("ISC", popularRepo, "npm", scopedNormalPkg), `"a-happy-tyler"`, `"`+scopedNormalPkg+`"`, 1),
+			expected: []string{ciErrorAt(file, 2, 3, "name: Does not match pattern '"+nameRegex+"', ex. `scope-normal` for the scoped npm package `"+scopedNormalPkg+"`")},
+		},
+
+		{
+			name:     "suggest a library name for an invalid name",
+			input:    strings.Replace(licensedPkg("MIT", popularRepo, "npm", normalPkg), `"a-happy-tyler"`, `"a happy/tyler"`, 1),
+			expected: []string{ciErrorAt(file, 2, 3, "name: Does not match pattern '"+nameRegex+"', ex. `a-happy-tyler`")},
+		},
+
+		{
+			name: "invalid JSON is annotated at the invalid character",
+			input: `{
+    "name": "a-happy-tyler",
+    "keywords": [,]
+}`,
+			expected: []string{ciErrorAt(file, 3, 18, "failed to parse "+file+": invalid character ',' looking for beginning of value")},
+		},
+
 		{
 			name: "legacy NpmName and NpmFileMap should error",
 			input: `{
@@ -400,11 +725,8 @@ func TestCheckerLint(t *testing.T) {
 		}`,
 			expected: []string{
 				ciError(file, "(root): autoupdate is required"),
-				ciError(file, "(root): Additional property npmName is not allowed"),
-				ciError(file, "(root): Additional property npmFileMap is not allowed"),
-				ciError(file, "(root): autoupdate is required"),
-				ciError(file, "(root): Additional property npmFileMap is not allowed"),
-				ciError(file, "(root): Additional property npmName is not allowed"),
+				ciErrorAt(file, 22, 4, "(root): Additional property npmName is not allowed"),
+				ciErrorAt(file, 23, 4, "(root): Additional property npmFileMap is not allowed"),
 			},
 		},
 	}
@@ -433,10 +755,21 @@ func TestCheckerLint(t *testing.T) {
 				assert.Nil(t, err)
 			}
 
-			out := runChecker(fakeBotPath, httpTestProxy, tc.validatePath, "lint", pkgFile)
+			args := []string{"lint", pkgFile}
+			if tc.exceptions != "" {
+				exceptionsFile := path.Join(fakeBotPath, "exceptions.json")
+				assert.Nil(t, ioutil.WriteFile(exceptionsFile, []byte(tc.exceptions), 0644))
+				defer os.Remove(exceptionsFile)
+				args = append([]string{"-exceptions", exceptionsFile}, args...)
+			}
+
+			out := runChecker(fakeBotPath, httpTestProxy, tc.validatePath, args...)
 			for _, text := range tc.expected {
 				assert.Contains(t, out, strings.ReplaceAll(text, "\n", ""))
 			}
+			for _, text := range tc.unexpected {
+				assert.NotContains(t, out, strings.ReplaceAll(text, "\n", ""))
+			}
 
 			os.Remove(pkgFile)
 		})
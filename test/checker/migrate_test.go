@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyPkg = `{
+    "name": "a-happy-tyler",
+    "description": "Tyler is happy. Be like Tyler.",
+    "keywords": ["tyler", "happy"],
+    "author": "Tyler Caslin",
+    "license": "MIT",
+    "repository": {"type": "git", "url": "git@github.com:tc80/a-happy-tyler.git"},
+    "npmName": "a-happy-tyler",
+    "npmFileMap": [{"basePath": "", "files": ["*"]}]
+}`
+
+func TestCheckerMigrate(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	dir := path.Join(fakeBotPath, "packages", "packages")
+	file := path.Join(dir, "a", "a-happy-tyler.json")
+	assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(file, []byte(legacyPkg), 0644))
+
+	// dry-run prints the diff without rewriting the file
+	out := runChecker(fakeBotPath, "", true, "migrate", "-dry-run", dir)
+	assert.Contains(t, out, "migrations 1 (move the legacy npmName and npmFileMap into autoupdate), 2 (replace the legacy author string by authors)\n")
+	assert.Contains(t, out, "--- "+file+"\n+++ "+file+"\n")
+	assert.Contains(t, out, "-    \"npmName\": \"a-happy-tyler\",\n")
+	assert.Contains(t, out, "+  \"authors\": [\n")
+
+	content, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, legacyPkg, string(content))
+
+	// only the first migration
+	out = runChecker(fakeBotPath, "", true, "migrate", "-to", "1", "-dry-run", dir)
+	assert.Contains(t, out, ciError(file, "package is invalid after migrations 1 (move the legacy npmName and npmFileMap into autoupdate)"))
+	assert.Contains(t, out, ciError(file, "(root): Additional property author is not allowed"))
+
+	out = runChecker(fakeBotPath, "", true, "migrate", dir)
+	assert.Contains(t, out, file+": applied migration 2 (replace the legacy author string by authors)")
+
+	content, err = ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, formattedPkg, string(content))
+
+	// migrations are idempotent
+	out = runChecker(fakeBotPath, "", true, "migrate", "-dry-run", file)
+	assert.Equal(t, "", out)
+}
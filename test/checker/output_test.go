@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Message  string `json:"message"`
+}
+
+func TestCheckerJSONFormat(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	file := path.Join(fakeBotPath, "packages", "packages", "a", "a-happy-tyler.json")
+	assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(file, []byte(unformattedPkg), 0644))
+
+	out := runChecker(fakeBotPath, "", true, "-format", "json", "fmt", "-check", file)
+
+	var res struct {
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(out), &res), out)
+	assert.Equal(t, []diagnostic{
+		{
+			Severity: "error",
+			Code:     "not-formatted",
+			File:     file,
+			Line:     1,
+			Col:      1,
+			Message:  "package is not formatted, run `checker fmt`",
+		},
+	}, res.Diagnostics)
+
+	assert.Nil(t, ioutil.WriteFile(file, []byte(formattedPkg), 0644))
+	out = runChecker(fakeBotPath, "", true, "-format", "json", "fmt", "-check", file)
+	assert.Equal(t, "{\n  \"diagnostics\": []\n}\n", out)
+}
+
+func TestCheckerSARIFFormat(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+	file := path.Join(fakeBotPath, "packages", "packages", "a", "a-happy-tyler.json")
+	assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+	assert.Nil(t, ioutil.WriteFile(file, []byte(`{
+  "name": "a-happy-tyler",
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": ["tyler", "happy"],
+  "license": "MIT",
+  "repository": {"type": "git", "url": "https://github.com/tc80/a-happy-tyler.git"},
+  "autoupdate": {
+    "source": "npm",
+    "target": "a-happy-tyler",
+    "fileMap": [{"basePath": "", "files": ["*"]}]
+  },
+  "foo": "bar"
+}`), 0644))
+
+	out := runChecker(fakeBotPath, "", true, "-format", "sarif", "fmt", "-check", file)
+
+	var res struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(out), &res), out)
+	assert.Equal(t, "2.1.0", res.Version)
+	assert.Len(t, res.Runs, 1)
+	assert.NotEmpty(t, res.Runs[0].Tool.Driver.Rules)
+	assert.Len(t, res.Runs[0].Results, 1)
+
+	result := res.Runs[0].Results[0]
+	assert.Equal(t, "invalid-schema", result.RuleID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "(root): Additional property foo is not allowed", result.Message.Text)
+	assert.Len(t, result.Locations, 1)
+	assert.Equal(t, file, result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 12, result.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 3, result.Locations[0].PhysicalLocation.Region.StartColumn)
+}
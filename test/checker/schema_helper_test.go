@@ -10,9 +10,12 @@ import (
 
 const (
 	autoupdateSourceRegex = "^git|npm$"
+	destinationRegex      = "^[a-zA-Z0-9_@+-][a-zA-Z0-9_@+.-]*(/[a-zA-Z0-9_@+-][a-zA-Z0-9_@+.-]*)*$"
 	licenseRegex          = "^(\\(.+ (OR|AND) .+\\)|[a-zA-Z0-9-].*)$"
 	nameRegex             = "^[a-zA-Z0-9._-]+$"
+	prereleaseRegex       = "^(stable|latest-prerelease|all)$"
 	repositoryTypeRegex   = "^git|hg|svn$"
+	suppressionCodeRegex  = "^[a-z0-9-]+$"
 )
 
 type SchemaTestCase struct {
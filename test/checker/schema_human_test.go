@@ -86,6 +86,10 @@ func TestHumanReadableSchema(t *testing.T) {
 			errors:   []string{"authors.0: name is required"},
 		},
 		// autoupdate valid
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/valid/destination.json",
+			valid:    true,
+		},
 		{
 			filePath: "schema_tests/human_schema_tests/autoupdate/valid/empty_basepath.json",
 			valid:    true,
@@ -98,6 +102,10 @@ func TestHumanReadableSchema(t *testing.T) {
 			filePath: "schema_tests/human_schema_tests/autoupdate/valid/multiple_files.json",
 			valid:    true,
 		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/valid/prerelease.json",
+			valid:    true,
+		},
 		{
 			filePath: "schema_tests/human_schema_tests/autoupdate/valid/source_git.json",
 			valid:    true,
@@ -106,6 +114,10 @@ func TestHumanReadableSchema(t *testing.T) {
 			filePath: "schema_tests/human_schema_tests/autoupdate/valid/source_npm.json",
 			valid:    true,
 		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/valid/version_range.json",
+			valid:    true,
+		},
 		// autoupdate invalid
 		{
 			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/additional_properties.json",
@@ -114,6 +126,10 @@ func TestHumanReadableSchema(t *testing.T) {
 				"autoupdate.fileMap.0: Additional property directory is not allowed",
 			},
 		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/absolute_destination.json",
+			errors:   []string{"autoupdate.fileMap.0.destination: Does not match pattern '" + destinationRegex + "'"},
+		},
 		{
 			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/duplicate_filemap.json",
 			errors:   []string{"autoupdate.fileMap: array items[0,1] must be unique"},
@@ -122,6 +138,14 @@ func TestHumanReadableSchema(t *testing.T) {
 			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/duplicate_files.json",
 			errors:   []string{"autoupdate.fileMap.0.files: array items[0,1] must be unique"},
 		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/empty_destination.json",
+			errors:   []string{"autoupdate.fileMap.0.destination: Does not match pattern '" + destinationRegex + "'"},
+		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/empty_version_range.json",
+			errors:   []string{"autoupdate.versionRange: String length must be greater than or equal to 1"},
+		},
 		{
 			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/empty_file.json",
 			errors:   []string{"autoupdate.fileMap.0.files.0: String length must be greater than or equal to 1"},
@@ -166,6 +190,14 @@ func TestHumanReadableSchema(t *testing.T) {
 			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/missing_target.json",
 			errors:   []string{"autoupdate: target is required"},
 		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/parent_destination.json",
+			errors:   []string{"autoupdate.fileMap.0.destination: Does not match pattern '" + destinationRegex + "'"},
+		},
+		{
+			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/unknown_prerelease.json",
+			errors:   []string{"autoupdate.prerelease: Does not match pattern '" + prereleaseRegex + "'"},
+		},
 		{
 			filePath: "schema_tests/human_schema_tests/autoupdate/invalid/source_svn.json",
 			errors:   []string{"autoupdate.source: Does not match pattern '" + autoupdateSourceRegex + "'"},
@@ -365,6 +397,24 @@ func TestHumanReadableSchema(t *testing.T) {
 			filePath: "schema_tests/human_schema_tests/optimization/invalid/not_boolean.json",
 			errors:   []string{"optimization.js: Invalid type. Expected: boolean, given: string"},
 		},
+		// suppressions valid
+		{
+			filePath: "schema_tests/human_schema_tests/suppressions/valid/missing_filename.json",
+			valid:    true,
+		},
+		// suppressions invalid
+		{
+			filePath: "schema_tests/human_schema_tests/suppressions/invalid/empty_suppressions.json",
+			errors:   []string{"suppressions: Array must have at least 1 items"},
+		},
+		{
+			filePath: "schema_tests/human_schema_tests/suppressions/invalid/invalid_code.json",
+			errors:   []string{"suppressions.0.code: Does not match pattern '" + suppressionCodeRegex + "'"},
+		},
+		{
+			filePath: "schema_tests/human_schema_tests/suppressions/invalid/missing_justification.json",
+			errors:   []string{"suppressions.0: justification is required"},
+		},
 	}
 
 	runSchemaTestCases(t, packages.HumanReadableSchema, cases)
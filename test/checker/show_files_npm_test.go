@@ -4,8 +4,10 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -24,6 +26,9 @@ const (
 	sortByTimeStampPkg  = "sortByTimePkg"
 	symlinkPkg          = "symlinkPkg"
 	walkerPkg           = "walkerPkg"
+	patternsPkg         = "patternsPkg"
+	tamperedPkg         = "tamperedPkg"
+	scopedPkg           = "@scope/scopedPkg"
 	timeStamp1          = "1.0.0"
 	timeStamp2          = "2.0.0"
 	timeStamp3          = "3.0.0"
@@ -70,7 +75,8 @@ func addTarSymlink(tw *tar.Writer, path string, dst string) error {
 	return nil
 }
 
-func createTar(filemap map[string]VirtualFile) (*os.File, error) {
+// Creates a tarball with the files in a top directory, `package` on npm.
+func createTar(dir string, filemap map[string]VirtualFile) (*os.File, error) {
 	file, err := os.Create("/tmp/test.tgz")
 	if err != nil {
 		return nil, err
@@ -83,12 +89,12 @@ func createTar(filemap map[string]VirtualFile) (*os.File, error) {
 	// add each file as needed into the current tar archive
 	for path, virtualFile := range filemap {
 		if virtualFile.LinkTo != "" {
-			if err := addTarSymlink(tw, "package/"+path, virtualFile.LinkTo); err != nil {
+			if err := addTarSymlink(tw, dir+"/"+path, virtualFile.LinkTo); err != nil {
 				return nil, err
 			}
 		}
 		if virtualFile.Content != "" {
-			if err := addTarFile(tw, "package/"+path, virtualFile.Content); err != nil {
+			if err := addTarFile(tw, dir+"/"+path, virtualFile.Content); err != nil {
 				return nil, err
 			}
 		}
@@ -98,7 +104,11 @@ func createTar(filemap map[string]VirtualFile) (*os.File, error) {
 }
 
 func servePackage(w http.ResponseWriter, r *http.Request, filemap map[string]VirtualFile) {
-	file, err := createTar(filemap)
+	serveTar(w, r, "package", filemap)
+}
+
+func serveTar(w http.ResponseWriter, r *http.Request, dir string, filemap map[string]VirtualFile) {
+	file, err := createTar(dir, filemap)
 	if err != nil {
 		panic(err)
 	}
@@ -237,6 +247,55 @@ func fakeNpmHandlerShowFiles(w http.ResponseWriter, r *http.Request) {
 				"latest": "0.0.2"
 			}
 		}`)
+	case "/" + patternsPkg:
+		fmt.Fprint(w, `{
+			"versions": {
+				"0.0.2": {
+					"dist": {
+						"tarball": "http://registry.npmjs.org/`+patternsPkg+`.tgz"
+					}
+				}
+			},
+			"time": { "0.0.2": "2012-06-19T04:01:32.220Z" },
+			"dist-tags": {
+				"latest": "0.0.2"
+			}
+		}`)
+	case "/" + tamperedPkg:
+		fmt.Fprint(w, `{
+			"versions": {
+				"1.0.0": {
+					"dist": {
+						"tarball": "http://registry.npmjs.org/`+jsFilesPkg+`.tgz",
+						"integrity": "sha512-`+strings.Repeat("A", 86)+`=="
+					}
+				}
+			},
+			"time": { "1.0.0": "2012-06-19T04:01:32.220Z" },
+			"dist-tags": {
+				"latest": "1.0.0"
+			}
+		}`)
+	case "/" + scopedPkg:
+		// the slash of the scope is encoded, like the registry expects
+		if r.URL.RawPath != "/@scope%2FscopedPkg" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"Not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"versions": {
+				"1.0.0": {
+					"dist": {
+						"tarball": "http://registry.npmjs.org/`+scopedPkg+`/-/scopedPkg-1.0.0.tgz"
+					}
+				}
+			},
+			"time": { "1.0.0": "2012-06-19T04:01:32.220Z" },
+			"dist-tags": {
+				"latest": "1.0.0"
+			}
+		}`)
 	case "/" + jsFilesPkg + ".tgz":
 		servePackage(w, r, map[string]VirtualFile{
 			"a.js": {Content: "a"},
@@ -285,11 +344,45 @@ func fakeNpmHandlerShowFiles(w http.ResponseWriter, r *http.Request) {
 			"../../b.js":    {Content: "b"},
 			"../../../c.js": {Content: "c"},
 		})
+	case "/" + scopedPkg + "/-/scopedPkg-1.0.0.tgz":
+		// the top directory of some packages isn't named `package`
+		serveTar(w, r, "scopedPkg", map[string]VirtualFile{
+			"a.js":     {Content: "a"},
+			"lib/b.js": {Content: "b"},
+		})
+	case "/" + patternsPkg + ".tgz":
+		files := map[string]VirtualFile{
+			"dist/a.js":      {Content: "a"},
+			"dist/a.js.map":  {Content: "a"},
+			"src/a.js":       {Content: "a"},
+			"test/a.test.js": {Content: "a"},
+			"lib/b.js.map":   {Content: "b"},
+		}
+		for i := 0; i < 1000; i++ {
+			files[fmt.Sprintf("many/%d.js", i)] = VirtualFile{Content: "m"}
+		}
+		servePackage(w, r, files)
 	default:
 		panic("unreachable: " + r.URL.Path)
 	}
 }
 
+// Returns the row of a published file in the table of sizes, without the sandbox.
+func fileRow(name, size, gzip string) string {
+	return fmt.Sprintf("| `%s` | %s | %s | - |\n", name, size, gzip)
+}
+
+// Returns the table of the sizes of published files, without the sandbox.
+func sizesTable(total, gzip string, rows ...string) string {
+	return "| file | size | gzip | brotli |\n| --- | ---: | ---: | ---: |\n" + strings.Join(rows, "") +
+		fmt.Sprintf("\ntotal: %s (gzip %s, brotli -)", total, gzip)
+}
+
+// Returns the table of the files matched by each pattern in the last versions.
+func patternsTable(versions string, rows ...string) string {
+	return "\nfiles matched per pattern (" + versions + "):\n\n| basePath | pattern | files |\n| --- | --- | --- |\n" + strings.Join(rows, "")
+}
+
 func TestCheckerNPMShowFiles(t *testing.T) {
 	fakeBotPath := createFakeBotPath()
 	defer os.RemoveAll(fakeBotPath)
@@ -331,15 +424,14 @@ func TestCheckerNPMShowFiles(t *testing.T) {
 		}`,
 			expected: `
 
+files listed without the sandbox, minified files are not included
+
 most recent version: 0.0.2
 
-` + "```" + `
-a.js
-b.js
-` + "```" + `
+` + sizesTable("2 B", "44 B", fileRow("a.js", "1 B", "22 B"), fileRow("b.js", "1 B", "22 B")) + `
 
 0 last version(s):
-`,
+` + patternsTable("0.0.2", "| (root) | `*.js` | 2 |\n"),
 		},
 
 		{
@@ -375,15 +467,14 @@ b.js
 		}`,
 			expected: `
 
+files listed without the sandbox, minified files are not included
+
 most recent version: 0.0.2
 
-` + "```" + `
-a.js
-b.js
-` + "```" + `
+` + sizesTable("2 B", "44 B", fileRow("a.js", "1 B", "22 B"), fileRow("b.js", "1 B", "22 B")) + `
 ` + ciError(file, "Filename `not_included.js` not found in most recent version `0.0.2`.%0A") + `
 0 last version(s):
-`,
+` + patternsTable("0.0.2", "| (root) | `*.js` | 2 |\n"),
 		},
 
 		{
@@ -419,14 +510,14 @@ b.js
 		}`,
 			expected: `
 
+files listed without the sandbox, minified files are not included
+
 most recent version: 0.0.2
-` + ciWarn(file, "file a.js ignored due to byte size (26214500 > 26214400)") + `
-` + "```" + `
-b.js
-` + "```" + `
+
+` + sizesTable("2 B", "23 B", fileRow("b.js", "2 B", "23 B")) + `
 
 0 last version(s):
-`,
+` + patternsTable("0.0.2", "| (root) | `*.js` | 2 |\n"),
 		},
 
 		{
@@ -462,16 +553,14 @@ b.js
 		}`,
 			expected: `
 
+files listed without the sandbox, minified files are not included
+
 most recent version: 1.3.1
 
-` + "```" + `
-a.js
-b.js
-c.js
-` + "```" + `
+` + sizesTable("3 B", "66 B", fileRow("a.js", "1 B", "22 B"), fileRow("b.js", "1 B", "22 B"), fileRow("c.js", "1 B", "22 B")) + `
 
 0 last version(s):
-`,
+` + patternsTable("1.3.1", "| (root) | `*.js` | 3 |\n"),
 		},
 
 		{
@@ -507,18 +596,22 @@ c.js
 		}`,
 			expected: `
 
+files listed without the sandbox, minified files are not included
+
 most recent version: 2.0.0
 
-` + "```" + `
-2.js
-` + "```" + `
+` + sizesTable("19 B", "40 B", fileRow("2.js", "19 B", "40 B")) + `
 
 4 last version(s):
-- 3.0.0: 1 file(s) matched :heavy_check_mark:
-- 1.0.0: 1 file(s) matched :heavy_check_mark:
-- 5.0.0: 1 file(s) matched :heavy_check_mark:
-- 4.0.0: 1 file(s) matched :heavy_check_mark:
-`,
+- 3.0.0: 1 file(s) matched, 23 B :heavy_check_mark:
+- 1.0.0: 1 file(s) matched, 23 B :heavy_check_mark:
+- 5.0.0: 1 file(s) matched, 23 B :heavy_check_mark:
+- 4.0.0: 1 file(s) matched, 23 B :heavy_check_mark:
+
+since 3.0.0: 1 file(s) added, 1 file(s) removed
+  + 2.js
+  - 3.js :heavy_exclamation_mark:
+` + patternsTable("2.0.0, 3.0.0, 1.0.0, 5.0.0, 4.0.0", "| (root) | `*.js` | 1, 1, 1, 1, 1 |\n"),
 		},
 	}
 
@@ -527,8 +620,12 @@ most recent version: 2.0.0
 		Handler: http.Handler(http.HandlerFunc(fakeNpmHandlerShowFiles)),
 	}
 
+	// listen before the first case runs
+	ln, err := net.Listen("tcp", httpTestProxy)
+	assert.Nil(t, err)
+
 	go func() {
-		if err := testproxy.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := testproxy.Serve(ln); err != nil && err != http.ErrServerClosed {
 			panic(err)
 		}
 	}()
@@ -546,12 +643,8 @@ most recent version: 2.0.0
 				assert.Nil(t, err)
 			}
 
-			// TODO: mock sandbox
-			_ = tc.expected
-			_ = tc.validatePath
-			//
-			// out := runChecker(fakeBotPath, httpTestProxy, tc.validatePath, "show-files", pkgFile)
-			// assert.Equal(t, tc.expected, "\n"+out)
+			out := runCheckerStdout(fakeBotPath, httpTestProxy, tc.validatePath, "-no-sandbox", "show-files", pkgFile)
+			assert.Equal(t, tc.expected, "\n"+out)
 
 			os.Remove(pkgFile)
 		})
@@ -597,9 +690,7 @@ func TestCheckerShowFilesNPMSymlink(t *testing.T) {
 	}`
 	expected := `most recent version: 0.0.2
 
-` + "```" + `
-c.js
-` + "```" + ``
+` + sizesTable("12 B", "33 B", fileRow("c.js", "12 B", "33 B")) + ``
 
 	err := ioutil.WriteFile(pkgFile, []byte(input), 0644)
 	assert.Nil(t, err)
@@ -616,11 +707,8 @@ c.js
 		}
 	}()
 
-	// TODO: mock sandbox
-	_ = expected
-	//
-	// out := runChecker(fakeBotPath, httpTestProxy, false, "show-files", pkgFile)
-	// assert.Contains(t, out, expected)
+	out := runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", pkgFile)
+	assert.Contains(t, out, expected)
 	assert.Nil(t, testproxy.Shutdown(context.Background()))
 }
 
@@ -660,9 +748,7 @@ func TestCheckerShowFilesTarWalker(t *testing.T) {
 		}
 	}`
 	expected := []string{`
-` + "```" + `
-a.js
-` + "```" + ``,
+` + sizesTable("1 B", "22 B", fileRow("a.js", "1 B", "22 B")) + ``,
 		"Unsafe file located outside", "with name: `package/../../b.js`",
 		"Unsafe file located outside", "with name: `package/../../../c.js`",
 	}
@@ -682,11 +768,413 @@ a.js
 		}
 	}()
 
-	// TODO: mock sandbox
-	_ = expected
-	// out := runChecker(fakeBotPath, httpTestProxy, false, "show-files", pkgFile)
-	// for _, text := range expected {
-	// 	assert.Contains(t, out, text)
-	// }
+	out := runChecker(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", pkgFile)
+	for _, text := range expected {
+		assert.Contains(t, out, text)
+	}
+	assert.Nil(t, testproxy.Shutdown(context.Background()))
+}
+
+func TestCheckerShowFilesTargets(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	httpTestProxy := "localhost:8666"
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "i", "input-show-files.json")
+	input := `{
+		"name": "a-happy-tyler",
+		"description": "Tyler is happy. Be like Tyler.",
+		"keywords": [
+			"tyler"
+		],
+		"license": "MIT",
+		"repository": {
+			"type": "git",
+			"url": "git://github.com/tc80/a-happy-tyler.git"
+		},
+		"filename": "2.js",
+		"autoupdate": {
+			"source": "npm",
+			"target": "` + sortByTimeStampPkg + `",
+			"fileMap": [
+				{ "basePath":"", "files":["*.js"] }
+			]
+		}
+	}`
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(input), 0644))
+	defer os.Remove(pkgFile)
+
+	testproxy := &http.Server{
+		Addr:    httpTestProxy,
+		Handler: http.Handler(http.HandlerFunc(fakeNpmHandlerShowFiles)),
+	}
+	ln, err := net.Listen("tcp", httpTestProxy)
+	assert.Nil(t, err)
+
+	go func() {
+		if err := testproxy.Serve(ln); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	// a version of the registry
+	out := runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", "-version", "3.0.0", pkgFile)
+	assert.Equal(t, `
+files listed without the sandbox, minified files are not included
+
+version: 3.0.0
+
+`+sizesTable("23 B", "44 B", fileRow("3.js", "23 B", "44 B"))+`
+`+ciError(pkgFile, "Filename `2.js` not found in version `3.0.0`.%0A"), out)
+
+	out = runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", "-version", "6.0.0", pkgFile)
+	assert.Contains(t, out, ciError(pkgFile, "version `6.0.0` not found on npm"))
+
+	// a local tarball
+	tarball, err := createTar("package", map[string]VirtualFile{
+		"2.js":     {Content: "2"},
+		"lib/3.js": {Content: "3"},
+	})
+	assert.Nil(t, err)
+	tarball.Close()
+	defer os.Remove(tarball.Name())
+
+	out = runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", "-tarball", tarball.Name(), pkgFile)
+	assert.Equal(t, `
+files listed without the sandbox, minified files are not included
+
+tarball: `+tarball.Name()+`
+
+`+sizesTable("1 B", "22 B", fileRow("2.js", "1 B", "22 B"))+`
+`, out)
+
+	// a local directory
+	dir, err := ioutil.TempDir("", "show-files")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "1.js"), []byte("1"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "1.css"), []byte("1"), 0644))
+
+	out = runCheckerStdout(fakeBotPath, httpTestProxy, false, "show-files", "-dir", dir, pkgFile)
+	assert.Equal(t, `
+files listed without the sandbox, minified files are not included
+
+directory: `+dir+`
+
+`+sizesTable("1 B", "22 B", fileRow("1.js", "1 B", "22 B"))+`
+`+ciError(pkgFile, "Filename `2.js` not found in directory `"+dir+"`.%0A"), out)
+
+	// a single target
+	out = runChecker(fakeBotPath, httpTestProxy, false, "show-files", "-version", "3.0.0", "-dir", dir, pkgFile)
+	assert.Contains(t, out, "usage: checker show-files [-version V | -tarball file.tgz | -dir directory] <package>")
+
+	assert.Nil(t, testproxy.Shutdown(context.Background()))
+}
+
+func TestCheckerShowFilesSandbox(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "i", "input-show-files.json")
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(npmPkg(jsFilesPkg, `"*.js"`)), 0644))
+	defer os.Remove(pkgFile)
+
+	tarball, err := createTar("package", map[string]VirtualFile{
+		"b.js": {Content: "b"},
+	})
+	assert.Nil(t, err)
+	tarball.Close()
+	defer os.Remove(tarball.Name())
+
+	// without -no-sandbox, the sandbox is initialized before any version is processed
+	cmd := checkerCmd(fakeBotPath, "", false, "show-files", "-tarball", tarball.Name(), pkgFile)
+	cmd.Env = append(cmd.Env, "DOCKER_IMAGE=")
+	out, err := cmd.CombinedOutput()
+	assert.NotNil(t, err)
+	assert.Contains(t, string(out), "failed to init sandbox: DOCKER_IMAGE needs to be present")
+
+	cmd = checkerCmd(fakeBotPath, "", false, "diff", pkgFile, pkgFile)
+	cmd.Env = append(cmd.Env, "DOCKER_IMAGE=")
+	out, err = cmd.CombinedOutput()
+	assert.NotNil(t, err)
+	assert.Contains(t, string(out), "failed to init sandbox: DOCKER_IMAGE needs to be present")
+}
+
+func TestCheckerShowFilesSizes(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "i", "input-show-files.json")
+	input := `{
+		"name": "a-happy-tyler",
+		"description": "Tyler is happy. Be like Tyler.",
+		"keywords": [
+			"tyler"
+		],
+		"license": "MIT",
+		"repository": {
+			"type": "git",
+			"url": "git://github.com/tc80/a-happy-tyler.git"
+		},
+		"filename": "big.js",
+		"autoupdate": {
+			"source": "npm",
+			"target": "a-happy-tyler",
+			"fileMap": [
+				{ "basePath":"", "files":["*.js", "*.woff2"] }
+			]
+		}
+	}`
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(input), 0644))
+	defer os.Remove(pkgFile)
+
+	dir, err := ioutil.TempDir("", "show-files")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	big := strings.Repeat("a", int(util.MaxFileSize)-100)
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "big.js"), []byte(big), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "font.woff2"), []byte(strings.Repeat("f", 2048)), 0644))
+
+	// files close to the limit are flagged, and fonts aren't compressed
+	out := runCheckerStdout(fakeBotPath, "", false, "show-files", "-dir", dir, pkgFile)
+	assert.Contains(t, out, "| `big.js` :warning: close to the limit of 25.0 MiB | 25.0 MiB | ")
+	assert.Contains(t, out, "| `font.woff2` | 2.0 KiB | - | - |\n")
+	assert.Contains(t, out, "\ntotal: 25.0 MiB (gzip ")
+
+	out = runCheckerStdout(fakeBotPath, "", false, "-format", "json", "show-files", "-dir", dir, pkgFile)
+	var res struct {
+		ShowFiles struct {
+			Versions []struct {
+				Files     []string
+				TotalSize int64
+				Sizes     []struct {
+					Name        string
+					Size        int64
+					Gzip        *int64
+					NearMaxSize bool
+				}
+			}
+		}
+	}
+	assert.Nil(t, json.Unmarshal([]byte(out), &res))
+	assert.Equal(t, 1, len(res.ShowFiles.Versions))
+	v := res.ShowFiles.Versions[0]
+	assert.Equal(t, []string{"big.js", "font.woff2"}, v.Files)
+	assert.Equal(t, util.MaxFileSize-100+2048, v.TotalSize)
+	assert.True(t, v.Sizes[0].NearMaxSize)
+	assert.NotNil(t, v.Sizes[0].Gzip)
+	assert.False(t, v.Sizes[1].NearMaxSize)
+	assert.Nil(t, v.Sizes[1].Gzip)
+}
+
+func TestCheckerShowFilesSensitive(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "i", "input-show-files.json")
+	input := `{
+		"name": "a-happy-tyler",
+		"description": "Tyler is happy. Be like Tyler.",
+		"keywords": [
+			"tyler"
+		],
+		"license": "MIT",
+		"repository": {
+			"type": "git",
+			"url": "git://github.com/tc80/a-happy-tyler.git"
+		},
+		"filename": "a.js",
+		"autoupdate": {
+			"source": "npm",
+			"target": "a-happy-tyler",
+			"fileMap": [
+				{ "basePath":"", "files":["**/*", ".*"] }
+			]
+		}
+	}`
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(input), 0644))
+	defer os.Remove(pkgFile)
+
+	dir, err := ioutil.TempDir("", "show-files")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.Mkdir(path.Join(dir, "certs"), 0755))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "a.js"), []byte("console.log('a')"), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, ".env"), []byte("SECRET=1"), 0644))
+	key := "-----BEGIN " + "PRIVATE KEY-----\n" + strings.Repeat("MIIEpAIBAAKCAQEA", 5) + "\n-----END PRIVATE KEY-----\n"
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "certs", "b.js"), []byte(key), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "pem.js"), []byte(`s.indexOf("-----BEGIN `+`PRIVATE KEY-----")`), 0644))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "c (1).js"), []byte("_authToken=abc123"), 0644))
+
+	// sensitive files are reported and not listed
+	out := runCheckerStdout(fakeBotPath, "", false, "show-files", "-dir", dir, pkgFile)
+	assert.Contains(t, out, `file ".env" not published, it looks sensitive: environment file in directory `+dir)
+	assert.Contains(t, out, `file "certs/b.js" not published, it looks sensitive: contains private key in directory `+dir)
+	assert.Contains(t, out, "| `a.js` | ")
+	assert.NotContains(t, out, "| `.env` | ")
+	assert.NotContains(t, out, "| `certs/b.js` | ")
+
+	// while files which may contain a secret, or only a PEM header, are published
+	assert.Contains(t, out, `file "c (1).js" published, it may contain a secret: contains npm token in directory `+dir)
+	assert.Contains(t, out, "| `c (1).js` | ")
+	assert.Contains(t, out, "| `pem.js` | ")
+	assert.NotContains(t, out, `file "pem.js"`)
+}
+
+func TestCheckerShowFilesPatterns(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	httpTestProxy := "localhost:8666"
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "i", "input-show-files.json")
+	input := `{
+  "name": "a-happy-tyler",
+  "description": "Tyler is happy. Be like Tyler.",
+  "keywords": ["tyler"],
+  "license": "MIT",
+  "repository": {"type": "git", "url": "git://github.com/tc80/a-happy-tyler.git"},
+  "filename": "a.js",
+  "autoupdate": {
+    "source": "npm",
+    "target": "` + patternsPkg + `",
+    "fileMap": [
+      {"basePath": "", "files": ["**/*.js", "**/*.map", "*.css"]},
+      {"basePath": "dist", "files": ["*.js"]}
+    ]
+  }
+}`
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(input), 0644))
+	defer os.Remove(pkgFile)
+
+	testproxy := &http.Server{
+		Addr:    httpTestProxy,
+		Handler: http.Handler(http.HandlerFunc(fakeNpmHandlerShowFiles)),
+	}
+	ln, err := net.Listen("tcp", httpTestProxy)
+	assert.Nil(t, err)
+
+	go func() {
+		if err := testproxy.Serve(ln); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	out := runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", pkgFile)
+	expected := []string{
+		patternsTable("0.0.2",
+			"| (root) | `**/*.js` | 1003 |\n",
+			"| (root) | `**/*.map` | 2 |\n",
+			"| (root) | `*.css` | 0 |\n",
+			"| `dist` | `*.js` | 1 |\n",
+		),
+		ciWarnAt(pkgFile, 12, 34, "pattern `**/*.js` matches 1003 files in version 0.0.2"),
+		ciWarnAt(pkgFile, 12, 34, "pattern `**/*.js` matches 2 file(s) usually not published (tests, sources, dependencies or source maps of unpublished files), ex. `src/a.js`, `test/a.test.js`"),
+		ciWarnAt(pkgFile, 12, 45, "pattern `**/*.map` matches 1 file(s) usually not published (tests, sources, dependencies or source maps of unpublished files), ex. `lib/b.js.map`"),
+		ciWarnAt(pkgFile, 12, 57, "pattern `*.css` matches no file in the last 1 version(s)"),
+	}
+	for _, text := range expected {
+		assert.Contains(t, out, text)
+	}
+	assert.Nil(t, testproxy.Shutdown(context.Background()))
+}
+
+func TestCheckerShowFilesIntegrity(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	httpTestProxy := "localhost:8666"
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "i", "input-show-files.json")
+	input := `{
+		"name": "a-happy-tyler",
+		"description": "Tyler is happy. Be like Tyler.",
+		"keywords": [
+			"tyler"
+		],
+		"license": "MIT",
+		"repository": {
+			"type": "git",
+			"url": "git://github.com/tc80/a-happy-tyler.git"
+		},
+		"filename": "a.js",
+		"autoupdate": {
+			"source": "npm",
+			"target": "` + tamperedPkg + `",
+			"fileMap": [
+				{ "basePath":"", "files":["*.js"] }
+			]
+		}
+	}`
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(input), 0644))
+	defer os.Remove(pkgFile)
+
+	testproxy := &http.Server{
+		Addr:    httpTestProxy,
+		Handler: http.Handler(http.HandlerFunc(fakeNpmHandlerShowFiles)),
+	}
+	ln, err := net.Listen("tcp", httpTestProxy)
+	assert.Nil(t, err)
+
+	go func() {
+		if err := testproxy.Serve(ln); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	// a tarball which doesn't match its integrity isn't processed
+	out := runChecker(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", pkgFile)
+	assert.Contains(t, out, "failed to process version: failed to download version: could not verify http://registry.npmjs.org/"+jsFilesPkg+".tgz: integrity mismatch: expected sha512-"+strings.Repeat("A", 86)+"==, got sha512-")
+	assert.NotContains(t, out, "| `a.js` |")
+
+	assert.Nil(t, testproxy.Shutdown(context.Background()))
+}
+
+func TestCheckerShowFilesScoped(t *testing.T) {
+	fakeBotPath := createFakeBotPath()
+	defer os.RemoveAll(fakeBotPath)
+
+	httpTestProxy := "localhost:8666"
+	pkgFile := path.Join(fakeBotPath, "packages", "packages", "s", "scope-scopedPkg.json")
+	assert.Nil(t, os.MkdirAll(path.Dir(pkgFile), 0755))
+	input := `{
+		"name": "scope-scopedPkg",
+		"description": "Tyler is happy. Be like Tyler.",
+		"keywords": [
+			"tyler"
+		],
+		"license": "MIT",
+		"repository": {
+			"type": "git",
+			"url": "git://github.com/tc80/a-happy-tyler.git"
+		},
+		"filename": "a.js",
+		"autoupdate": {
+			"source": "npm",
+			"target": "` + scopedPkg + `",
+			"fileMap": [
+				{ "basePath":"", "files":["**/*.js"] }
+			]
+		}
+	}`
+	assert.Nil(t, ioutil.WriteFile(pkgFile, []byte(input), 0644))
+	defer os.Remove(pkgFile)
+
+	testproxy := &http.Server{
+		Addr:    httpTestProxy,
+		Handler: http.Handler(http.HandlerFunc(fakeNpmHandlerShowFiles)),
+	}
+	ln, err := net.Listen("tcp", httpTestProxy)
+	assert.Nil(t, err)
+
+	go func() {
+		if err := testproxy.Serve(ln); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	out := runCheckerStdout(fakeBotPath, httpTestProxy, false, "-no-sandbox", "show-files", pkgFile)
+	assert.Contains(t, out, "most recent version: 1.0.0")
+	assert.Contains(t, out, "| `a.js` | 1 B |")
+	assert.Contains(t, out, "| `lib/b.js` | 1 B |")
+
 	assert.Nil(t, testproxy.Shutdown(context.Background()))
 }
@@ -0,0 +1,42 @@
+#!/usr/bin/env node
+// Regenerates the expected matches of the glob conformance corpus by running
+// the node glob tool (https://github.com/cdnjs/glob) against each layout.
+//
+// Usage: node generate.js [path to glob/index.js]
+
+const { execFileSync } = require("child_process");
+const fs = require("fs");
+const os = require("os");
+const path = require("path");
+
+const tool = process.argv[2] || "/glob/index.js";
+const corpusDir = path.join(__dirname, "corpus");
+
+for (const name of fs.readdirSync(corpusDir).filter((f) => f.endsWith(".json"))) {
+  const file = path.join(corpusDir, name);
+  const corpus = JSON.parse(fs.readFileSync(file, "utf8"));
+  const root = fs.mkdtempSync(path.join(os.tmpdir(), "glob-corpus-"));
+
+  for (const f of corpus.files) {
+    fs.mkdirSync(path.dirname(path.join(root, f)), { recursive: true });
+    fs.writeFileSync(path.join(root, f), f);
+  }
+  for (const [link, target] of Object.entries(corpus.symlinks || {})) {
+    fs.mkdirSync(path.dirname(path.join(root, link)), { recursive: true });
+    fs.symlinkSync(target, path.join(root, link));
+  }
+
+  for (const c of corpus.cases) {
+    const out = execFileSync(tool, [c.pattern], {
+      cwd: path.join(root, c.basePath || ""),
+      encoding: "utf8",
+    });
+    c.matches = out
+      .split("\n")
+      .filter((l) => l.trim() !== "")
+      .sort((a, b) => (a < b ? -1 : a > b ? 1 : 0));
+  }
+
+  fs.rmSync(root, { recursive: true });
+  fs.writeFileSync(file, JSON.stringify(corpus, null, 2) + "\n");
+}
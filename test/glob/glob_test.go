@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/cdnjs/tools/glob"
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Corpus is a package layout and the files matched by patterns within it,
+// as listed by the node glob tool. Regenerate the matches with generate.js.
+type Corpus struct {
+	Description string            `json:"description"`
+	Files       []string          `json:"files"`
+	Symlinks    map[string]string `json:"symlinks"`
+	Cases       []struct {
+		BasePath string   `json:"basePath"`
+		Pattern  string   `json:"pattern"`
+		Matches  []string `json:"matches"`
+	} `json:"cases"`
+}
+
+// creates the corpus layout in a temporary directory
+func createLayout(t *testing.T, corpus Corpus) string {
+	root, err := ioutil.TempDir("", "glob-corpus")
+	assert.Nil(t, err)
+
+	for _, f := range corpus.Files {
+		file := path.Join(root, f)
+		assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+		assert.Nil(t, ioutil.WriteFile(file, []byte(f), 0644))
+	}
+	for link, target := range corpus.Symlinks {
+		file := path.Join(root, link)
+		assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+		assert.Nil(t, os.Symlink(target, file))
+	}
+
+	return root
+}
+
+func TestGlobCorpus(t *testing.T) {
+	files, err := filepath.Glob("corpus/*.json")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		bytes, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+
+		var corpus Corpus
+		assert.Nil(t, json.Unmarshal(bytes, &corpus))
+
+		root := createLayout(t, corpus)
+		defer os.RemoveAll(root)
+
+		for _, tc := range corpus.Cases {
+			tc := tc
+			t.Run(path.Base(file)+"/"+tc.Pattern, func(t *testing.T) {
+				matches := glob.List(path.Join(root, tc.BasePath), tc.Pattern)
+				assert.Equal(t, tc.Matches, matches)
+			})
+		}
+	}
+}
+
+func TestNpmFilesFrom(t *testing.T) {
+	root := createLayout(t, Corpus{
+		Files: []string{
+			"package.json",
+			"dist/jquery.js",
+			"dist/jquery.min.js",
+			"dist/.DS_Store",
+			"src/core.js",
+		},
+	})
+	defer os.RemoveAll(root)
+
+	pckg, err := packages.ReadHumanJSONBytes(context.Background(), "jquery.json", []byte(`{
+		"name": "jquery",
+		"autoupdate": {
+			"source": "npm",
+			"target": "jquery",
+			"fileMap": [
+				{ "basePath": "dist", "files": ["*.js", "jquery.{js,min.js}"] },
+				{ "basePath": "", "files": ["**/core.js"] }
+			]
+		}
+	}`), false)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []packages.NpmFileMoveOp{
+		{From: "dist/jquery.js", To: "jquery.js"},
+		{From: "dist/jquery.min.js", To: "jquery.min.js"},
+		{From: "src/core.js", To: "src/core.js"},
+	}, pckg.NpmFilesFrom(root))
+}
+
+func TestNpmFilesFromDestination(t *testing.T) {
+	root := createLayout(t, Corpus{
+		Files: []string{
+			"dist/umd/lib.js",
+			"dist/esm/lib.js",
+			"dist/css/lib.css",
+		},
+	})
+	defer os.RemoveAll(root)
+
+	pckg, err := packages.ReadHumanJSONBytes(context.Background(), "lib.json", []byte(`{
+		"name": "lib",
+		"autoupdate": {
+			"source": "npm",
+			"target": "lib",
+			"fileMap": [
+				{ "basePath": "dist/umd", "files": ["*.js"], "destination": "js" },
+				{ "basePath": "dist/css", "files": ["*.css"], "destination": "css/themes" },
+				{ "basePath": "dist/esm", "files": ["*.js"], "destination": "js" }
+			]
+		}
+	}`), false)
+	assert.Nil(t, err)
+
+	// dist/esm/lib.js collides with dist/umd/lib.js and is ignored
+	assert.Equal(t, []packages.NpmFileMoveOp{
+		{From: "dist/umd/lib.js", To: "js/lib.js"},
+		{From: "dist/css/lib.css", To: "css/themes/lib.css"},
+	}, pckg.NpmFilesFrom(root))
+
+	_, collisions := pckg.NpmFilesAndCollisionsFrom(root)
+	assert.Equal(t, []packages.DestinationCollision{
+		{From: "dist/esm/lib.js", To: "js/lib.js", Other: "dist/umd/lib.js"},
+	}, collisions)
+}
+
+func TestNpmFilesFromLegacyCollision(t *testing.T) {
+	root := createLayout(t, Corpus{
+		Files: []string{
+			"dist/umd/lib.js",
+			"dist/esm/lib.js",
+		},
+	})
+	defer os.RemoveAll(root)
+
+	pckg, err := packages.ReadHumanJSONBytes(context.Background(), "lib.json", []byte(`{
+		"name": "lib",
+		"autoupdate": {
+			"source": "npm",
+			"target": "lib",
+			"fileMap": [
+				{ "basePath": "dist/umd", "files": ["*.js"] },
+				{ "basePath": "dist/esm", "files": ["*.js"] }
+			]
+		}
+	}`), false)
+	assert.Nil(t, err)
+
+	// without destinations, every file is moved and the last one wins
+	ops, collisions := pckg.NpmFilesAndCollisionsFrom(root)
+	assert.Equal(t, []packages.NpmFileMoveOp{
+		{From: "dist/umd/lib.js", To: "lib.js"},
+		{From: "dist/esm/lib.js", To: "lib.js"},
+	}, ops)
+	assert.Equal(t, []packages.DestinationCollision{
+		{From: "dist/umd/lib.js", To: "lib.js", Other: "dist/esm/lib.js"},
+	}, collisions)
+}
+
+func TestNpmFilesFromMixedCollisions(t *testing.T) {
+	root := createLayout(t, Corpus{
+		Files: []string{
+			"dist/umd/lib.js",
+			"dist/esm/lib.js",
+			"dist/js/lib.js",
+			"dist/css/lib.css",
+		},
+	})
+	defer os.RemoveAll(root)
+
+	pckg, err := packages.ReadHumanJSONBytes(context.Background(), "lib.json", []byte(`{
+		"name": "lib",
+		"autoupdate": {
+			"source": "npm",
+			"target": "lib",
+			"fileMap": [
+				{ "basePath": "dist/css", "files": ["*.css"], "destination": "css" },
+				{ "basePath": "dist/umd", "files": ["*.js"] },
+				{ "basePath": "dist/esm", "files": ["*.js"] }
+			]
+		}
+	}`), false)
+	assert.Nil(t, err)
+
+	// the maps without destinations keep the last-wins behaviour,
+	// even though another map has a destination
+	ops, collisions := pckg.NpmFilesAndCollisionsFrom(root)
+	assert.Equal(t, []packages.NpmFileMoveOp{
+		{From: "dist/css/lib.css", To: "css/lib.css"},
+		{From: "dist/umd/lib.js", To: "lib.js"},
+		{From: "dist/esm/lib.js", To: "lib.js"},
+	}, ops)
+	assert.Equal(t, []packages.DestinationCollision{
+		{From: "dist/umd/lib.js", To: "lib.js", Other: "dist/esm/lib.js"},
+	}, collisions)
+
+	// a map with a destination colliding with a map without one is first-wins
+	pckg, err = packages.ReadHumanJSONBytes(context.Background(), "lib.json", []byte(`{
+		"name": "lib",
+		"autoupdate": {
+			"source": "npm",
+			"target": "lib",
+			"fileMap": [
+				{ "basePath": "dist", "files": ["js/*.js"] },
+				{ "basePath": "dist/umd", "files": ["*.js"], "destination": "js" }
+			]
+		}
+	}`), false)
+	assert.Nil(t, err)
+	ops, collisions = pckg.NpmFilesAndCollisionsFrom(root)
+	assert.Equal(t, []packages.NpmFileMoveOp{
+		{From: "dist/js/lib.js", To: "js/lib.js"},
+	}, ops)
+	assert.Equal(t, []packages.DestinationCollision{
+		{From: "dist/umd/lib.js", To: "js/lib.js", Other: "dist/js/lib.js"},
+	}, collisions)
+}
+
+func TestParseDestinationCollisions(t *testing.T) {
+	collisions := []packages.DestinationCollision{
+		{From: "dist/esm/lib.js", To: "js/lib.js", Other: "dist/umd/lib.js"},
+		{From: "b/x (1).js", To: "x (1).js", Other: "a/x (1).js"},
+		{From: `a/"quoted".js`, To: `"quoted".js`, Other: `b/"quoted".js`},
+	}
+	logs := ""
+	for _, collision := range collisions {
+		logs += "2021/01/01 00:00:00 " + collision.String() + "\n"
+	}
+	assert.Equal(t, collisions, packages.ParseDestinationCollisions(logs))
+}
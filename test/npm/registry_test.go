@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cdnjs/tools/npm"
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/version"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const abbreviatedMetadata = `{
+	"dist-tags": {"latest": "1.0.0"},
+	"modified": "2020-02-01T00:00:00.000Z",
+	"versions": {
+		"0.9.0": {"dist": {"tarball": "%[1]s/a/-/a-0.9.0.tgz"}},
+		"1.0.0": {"dist": {"tarball": "%[1]s/a/-/a-1.0.0.tgz", "integrity": "sha512-YQ==", "shasum": "86f7e437faa5a7fce15d1ddcb9eaeaea377667b8"}}
+	}
+}`
+
+const fullMetadata = `{
+	"dist-tags": {"latest": "1.0.0"},
+	"versions": {
+		"0.9.0": {"license": "MIT", "dist": {"tarball": "%[1]s/a/-/a-0.9.0.tgz"}},
+		"1.0.0": {"license": {"type": "ISC"}, "dist": {"tarball": "%[1]s/a/-/a-1.0.0.tgz", "integrity": "sha512-YQ==", "shasum": "86f7e437faa5a7fce15d1ddcb9eaeaea377667b8"}}
+	},
+	"time": {"0.9.0": "2020-01-01T00:00:00.000Z", "1.0.0": "2020-02-01T00:00:00.000Z"}
+}`
+
+// A registry with a package, `a`, and a scoped one, `@scope/a`, only served
+// with a token, and the downloads API. The abbreviated metadata is served when
+// accepted, like the public registry, and the full metadata otherwise.
+func fakeRegistry(t *testing.T, token string) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/downloads/") && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// the slash of scoped packages is encoded, like the public registry expects
+		if strings.Contains(r.URL.Path, "@scope/") && !strings.Contains(r.URL.RawPath, "@scope%2Fa") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/a", "/@scope/a":
+			if r.Header.Get("Accept") == "application/json" {
+				fmt.Fprintf(w, fullMetadata, srv.URL)
+			} else {
+				assert.Contains(t, r.Header.Get("Accept"), "application/vnd.npm.install-v1+json")
+				fmt.Fprintf(w, abbreviatedMetadata, srv.URL)
+			}
+		case "/a/latest", "/@scope/a/latest":
+			fmt.Fprint(w, `{"version": "1.0.0", "license": {"type": "ISC"}}`)
+		case "/downloads/point/last-month/a", "/downloads/point/last-month/@scope/a":
+			fmt.Fprint(w, `{"downloads": 1234}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"Not found"}`)
+		}
+	}))
+	return srv
+}
+
+func TestClientExists(t *testing.T) {
+	srv := fakeRegistry(t, "secret")
+	defer srv.Close()
+	c := npm.NewClient(srv.URL+"/", "secret")
+
+	exists, err := c.Exists("a")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	exists, err = c.Exists("b")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	// the token is required, and responses to requests with it aren't shared
+	_, err = npm.NewClient(srv.URL, "").Exists("a")
+	assert.NotNil(t, err)
+}
+
+func TestClientGetVersions(t *testing.T) {
+	srv := fakeRegistry(t, "secret")
+	defer srv.Close()
+	c := npm.NewClient(srv.URL, "secret")
+
+	// versions are listed from the abbreviated metadata, without their times
+	target := "a"
+	versions, latest, err := c.GetVersions(context.Background(), &packages.Autoupdate{Target: &target})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", *latest)
+	assert.Equal(t, 2, len(versions))
+	for _, v := range versions {
+		assert.Equal(t, fmt.Sprintf("%s/a/-/a-%s.tgz", srv.URL, v.Version), v.Tarball)
+		assert.True(t, v.Date.IsZero())
+
+		// old versions have no digests
+		if v.Version == "1.0.0" {
+			assert.Equal(t, "sha512-YQ==", v.Integrity)
+			assert.Equal(t, "86f7e437faa5a7fce15d1ddcb9eaeaea377667b8", v.Shasum)
+		} else {
+			assert.Equal(t, "", v.Integrity)
+			assert.Equal(t, "", v.Shasum)
+		}
+	}
+
+	assert.Nil(t, c.DateVersions(context.Background(), target, versions))
+	for _, v := range versions {
+		assert.False(t, v.Date.IsZero())
+	}
+}
+
+func TestClientGetVersionsErrors(t *testing.T) {
+	srv := fakeRegistry(t, "secret")
+	defer srv.Close()
+
+	// error responses aren't parsed as metadata
+	target := "a"
+	_, _, err := npm.NewClient(srv.URL, "").GetVersions(context.Background(), &packages.Autoupdate{Target: &target})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "401 Unauthorized")
+	}
+
+	missing := "b"
+	c := npm.NewClient(srv.URL, "secret")
+	_, _, err = c.GetVersions(context.Background(), &packages.Autoupdate{Target: &missing})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "404 Not Found")
+	}
+
+	// versions without a time stamp can't be dated
+	versions, _, err := c.GetVersions(context.Background(), &packages.Autoupdate{Target: &target})
+	assert.Nil(t, err)
+	versions = append(versions, version.Version{Version: "2.0.0"})
+	err = c.DateVersions(context.Background(), target, versions)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "no time stamp for npm version a/2.0.0", err.Error())
+	}
+}
+
+func TestClientGetLicense(t *testing.T) {
+	srv := fakeRegistry(t, "secret")
+	defer srv.Close()
+	c := npm.NewClient(srv.URL, "secret")
+
+	license, err := c.GetLicense("a")
+	assert.Nil(t, err)
+	assert.Equal(t, "ISC", license)
+}
+
+func TestClientGetMonthlyDownload(t *testing.T) {
+	srv := fakeRegistry(t, "secret")
+	defer srv.Close()
+	c := npm.NewClient(srv.URL, "secret")
+	c.DownloadsURL = srv.URL + "/downloads"
+
+	md, err := c.GetMonthlyDownload("a")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1234), md.Downloads)
+}
+
+func TestClientScoped(t *testing.T) {
+	srv := fakeRegistry(t, "secret")
+	defer srv.Close()
+	c := npm.NewClient(srv.URL, "secret")
+	c.DownloadsURL = srv.URL + "/downloads"
+
+	exists, err := c.Exists("@scope/a")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	target := "@scope/a"
+	versions, latest, err := c.GetVersions(context.Background(), &packages.Autoupdate{Target: &target})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", *latest)
+	assert.Equal(t, 2, len(versions))
+	assert.Nil(t, c.DateVersions(context.Background(), target, versions))
+
+	license, err := c.GetLicense("@scope/a")
+	assert.Nil(t, err)
+	assert.Equal(t, "ISC", license)
+
+	md, err := c.GetMonthlyDownload("@scope/a")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1234), md.Downloads)
+}
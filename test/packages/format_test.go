@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeRepositoryURL(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/cdnjs/tools.git":       "https://github.com/cdnjs/tools.git",
+		"https://github.com/cdnjs/tools":           "https://github.com/cdnjs/tools.git",
+		"https://GitHub.com/cdnjs/tools/":          "https://github.com/cdnjs/tools.git",
+		"git+https://github.com/cdnjs/tools.git":   "https://github.com/cdnjs/tools.git",
+		"git://github.com/cdnjs/tools.git":         "https://github.com/cdnjs/tools.git",
+		"http://github.com/cdnjs/tools":            "https://github.com/cdnjs/tools.git",
+		"git@github.com:cdnjs/tools.git":           "https://github.com/cdnjs/tools.git",
+		"ssh://git@github.com/cdnjs/tools.git":     "https://github.com/cdnjs/tools.git",
+		"git+ssh://git@github.com/cdnjs/tools.git": "https://github.com/cdnjs/tools.git",
+		"github:cdnjs/tools":                       "https://github.com/cdnjs/tools.git",
+		"gitlab:cdnjs/tools":                       "https://gitlab.com/cdnjs/tools.git",
+		" https://gitlab.com/cdnjs/tools ":         "https://gitlab.com/cdnjs/tools.git",
+		"svn://example.com/tools":                  "svn://example.com/tools",
+	}
+
+	for url, expected := range cases {
+		assert.Equal(t, expected, packages.NormalizeRepositoryURL(url), url)
+	}
+}
+
+func TestFormatHumanJSONBytes(t *testing.T) {
+	formatted, err := packages.FormatHumanJSONBytes(context.Background(), "lib.json", []byte(`{
+    "repository": {"type": "git", "url": "git+https://github.com/user/lib.git"},
+    "name": "lib",
+    "keywords": ["lib", "css", "lib"],
+    "description": "A <lib>.",
+    "autoupdate": {"target": "lib", "source": "npm", "fileMap": [{"files": ["*.js"], "basePath": "dist"}]},
+    "authors": [{"name": "A"}]
+}`))
+	assert.Nil(t, err)
+	assert.Equal(t, `{
+  "authors": [
+    {
+      "name": "A"
+    }
+  ],
+  "autoupdate": {
+    "source": "npm",
+    "target": "lib",
+    "fileMap": [
+      {
+        "basePath": "dist",
+        "files": [
+          "*.js"
+        ]
+      }
+    ]
+  },
+  "description": "A <lib>.",
+  "keywords": [
+    "css",
+    "lib"
+  ],
+  "name": "lib",
+  "repository": {
+    "type": "git",
+    "url": "https://github.com/user/lib.git"
+  }
+}
+`, string(formatted))
+
+	// the canonical form is stable
+	again, err := packages.FormatHumanJSONBytes(context.Background(), "lib.json", formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, string(formatted), string(again))
+
+	_, err = packages.FormatHumanJSONBytes(context.Background(), "lib.json", []byte(`{"name": "lib"}`))
+	assert.IsType(t, packages.InvalidSchemaError{}, err)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyPkg = `{
+    "name": "lib",
+    "description": "A lib.",
+    "keywords": ["lib"],
+    "author": "A <a@example.com> (https://example.com/a), B (https://example.com/b)",
+    "repository": "git+https://github.com/user/lib.git",
+    "npmName": "lib",
+    "npmFileMap": [{"basePath": "dist", "files": ["*.js"]}]
+}`
+
+func migrate(t *testing.T, from, to int) (map[string]interface{}, []int, error) {
+	migrated, applied, err := packages.MigrateHumanJSONBytes(context.Background(), "lib.json", []byte(legacyPkg), from, to)
+
+	versions := make([]int, 0)
+	for _, m := range applied {
+		versions = append(versions, m.Version)
+	}
+	if err != nil {
+		return nil, versions, err
+	}
+
+	var pkg map[string]interface{}
+	assert.Nil(t, json.Unmarshal(migrated, &pkg))
+	return pkg, versions, nil
+}
+
+func TestMigrations(t *testing.T) {
+	pkg, applied, err := migrate(t, 0, packages.LatestMigration())
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, applied)
+
+	assert.Equal(t, map[string]interface{}{
+		"source": "npm",
+		"target": "lib",
+		"fileMap": []interface{}{
+			map[string]interface{}{"basePath": "dist", "files": []interface{}{"*.js"}},
+		},
+	}, pkg["autoupdate"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "A", "email": "a@example.com", "url": "https://example.com/a"},
+		map[string]interface{}{"name": "B", "url": "https://example.com/b"},
+	}, pkg["authors"])
+	assert.Equal(t, map[string]interface{}{
+		"type": "git",
+		"url":  "https://github.com/user/lib.git",
+	}, pkg["repository"])
+	assert.NotContains(t, pkg, "author")
+	assert.NotContains(t, pkg, "npmName")
+	assert.NotContains(t, pkg, "npmFileMap")
+}
+
+func TestMigrationsRange(t *testing.T) {
+	// the repository string remains invalid
+	_, applied, err := migrate(t, 0, 2)
+	assert.IsType(t, packages.InvalidSchemaError{}, err)
+	assert.Equal(t, []int{1, 2}, applied)
+
+	// nothing to apply, the package is returned as is
+	migrated, none, err := packages.MigrateHumanJSONBytes(context.Background(), "lib.json", []byte(legacyPkg), 3, 3)
+	assert.Nil(t, err)
+	assert.Empty(t, none)
+	assert.Equal(t, legacyPkg, string(migrated))
+}
+
+func TestMigrationsOrdered(t *testing.T) {
+	for i, m := range packages.Migrations {
+		assert.Equal(t, i+1, m.Version)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cdnjs/tools/kv"
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsScoped(t *testing.T) {
+	assert.True(t, packages.IsScoped("@scope/name"))
+	assert.False(t, packages.IsScoped("name"))
+	assert.False(t, packages.IsScoped("@name"))
+}
+
+func TestLibraryName(t *testing.T) {
+	cases := map[string]string{
+		"name":             "name",
+		"a.js":             "a.js",
+		"Name_1-2":         "Name_1-2",
+		"@scope/name":      "scope-name",
+		"@my-scope/a.js":   "my-scope-a.js",
+		"@scope/name+plus": "scope-name-plus",
+		"a happy/tyler/":   "a-happy-tyler",
+	}
+	for name, library := range cases {
+		assert.Equal(t, library, packages.LibraryName(name), name)
+		assert.True(t, packages.IsLibraryName(packages.LibraryName(name)), name)
+	}
+	assert.False(t, packages.IsLibraryName("@scope/name"))
+	assert.False(t, packages.IsLibraryName(""))
+}
+
+// Returns a package updated from npm, with a name and a target.
+func npmPackage(t *testing.T, name, target string) *packages.Package {
+	var p packages.Package
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"name": "`+name+`",
+		"autoupdate": {
+			"source": "npm",
+			"target": "`+target+`",
+			"fileMap": [{"basePath": "dist", "files": ["**/*.js"]}]
+		}
+	}`), &p))
+	return &p
+}
+
+func TestSuggestedName(t *testing.T) {
+	// the scoped npm target is mapped, since its name is usually copied
+	p := npmPackage(t, "@scope/name", "@scope/name")
+	target, ok := packages.ScopedNpmTarget(p)
+	assert.True(t, ok)
+	assert.Equal(t, "@scope/name", target)
+	assert.Equal(t, "scope-name", packages.SuggestedName(p))
+
+	p = npmPackage(t, "a happy tyler", "a-happy-tyler")
+	_, ok = packages.ScopedNpmTarget(p)
+	assert.False(t, ok)
+	assert.Equal(t, "a-happy-tyler", packages.SuggestedName(p))
+}
+
+// Creates a gzipped tarball with the files in a top directory.
+func createTarball(t *testing.T, dir string, files map[string]string) []byte {
+	var buff bytes.Buffer
+	gw := gzip.NewWriter(&buff)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{
+			Name:     dir + "/" + name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+	return buff.Bytes()
+}
+
+func TestScopedNpmPackage(t *testing.T) {
+	p := npmPackage(t, "scope-name", "@scope/name")
+
+	// the top directory of tarballs of scoped packages can be named after them
+	tarball := createTarball(t, "name", map[string]string{
+		"dist/a.js":     "a",
+		"dist/lib/b.js": "b",
+		"src/c.js":      "c",
+	})
+	dir, err := ioutil.TempDir("", "scoped")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, packages.ExtractTarball(bytes.NewReader(tarball), "npm", dir))
+
+	var keys []string
+	for _, op := range p.NpmFilesFrom(dir) {
+		keys = append(keys, kv.FileKey(*p.Name, "1.0.0", op.To))
+	}
+	assert.ElementsMatch(t, []string{"scope-name/1.0.0/a.js", "scope-name/1.0.0/lib/b.js"}, keys)
+	assert.Equal(t, "scope-name/1.0.0", kv.VersionKey(*p.Name, "1.0.0"))
+}
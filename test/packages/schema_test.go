@@ -0,0 +1,23 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the committed schemas must be regenerated with `make schema`
+// whenever the struct tags of packages.Package change
+func TestCommittedSchemas(t *testing.T) {
+	human, err := ioutil.ReadFile("../../schema_human.json")
+	assert.Nil(t, err)
+	assert.Equal(t, packages.HumanReadableSchemaString, strings.TrimRight(string(human), "\n"))
+
+	nonHuman, err := ioutil.ReadFile("../../schema_non_human.json")
+	assert.Nil(t, err)
+	assert.Equal(t, packages.NonHumanReadableSchemaString, strings.TrimRight(string(nonHuman), "\n"))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// creates a local checkout of the packages repository
+func createCheckout(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "packages")
+	assert.Nil(t, err)
+
+	for name, content := range files {
+		file := path.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(path.Dir(file), os.ModePerm))
+		assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com",
+	}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
+
+func packageNames(t *testing.T, source packages.PackageSource) []string {
+	list, err := source.Packages(context.Background())
+	assert.Nil(t, err)
+
+	names := make([]string, 0)
+	for _, pkg := range list {
+		names = append(names, *pkg.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDirSource(t *testing.T) {
+	dir := createCheckout(t, map[string]string{
+		"packages/a/a-happy-tyler.json": `{"name": "a-happy-tyler"}`,
+		"packages/j/jquery.json":        `{"name": "jquery", "filename": "jquery.min.js"}`,
+		"packages/j/README.md":          "not a package",
+		"package.json":                  `{"name": "packages"}`,
+	})
+	defer os.RemoveAll(dir)
+
+	source := packages.NewDirSource(dir)
+	assert.Equal(t, []string{"a-happy-tyler", "jquery"}, packageNames(t, source))
+
+	// the lookup doesn't load the packages again
+	assert.Nil(t, os.RemoveAll(path.Join(dir, "packages")))
+
+	pkg, err := source.Package(context.Background(), "jquery")
+	assert.Nil(t, err)
+	assert.Equal(t, "jquery.min.js", *pkg.Filename)
+
+	_, err = source.Package(context.Background(), "unknown")
+	assert.NotNil(t, err)
+}
+
+func TestDirSourceInvalidPackage(t *testing.T) {
+	dir := createCheckout(t, map[string]string{
+		"packages/j/jquery.json": `{"name": `,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := packages.NewDirSource(dir).Packages(context.Background())
+	assert.NotNil(t, err)
+}
+
+func TestGitRefSource(t *testing.T) {
+	dir := createCheckout(t, map[string]string{
+		"packages/j/jquery.json": `{"name": "jquery"}`,
+	})
+	defer os.RemoveAll(dir)
+
+	git(t, dir, "init", "-q")
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "first")
+	git(t, dir, "tag", "first")
+
+	// uncommitted changes are ignored
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "packages/j/jquery-ui.json"), []byte(`{"name": "jquery-ui"}`), 0644))
+	assert.Equal(t, []string{"jquery"}, packageNames(t, packages.NewGitRefSource(dir, "HEAD")))
+
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "second")
+	assert.Equal(t, []string{"jquery", "jquery-ui"}, packageNames(t, packages.NewGitRefSource(dir, "HEAD")))
+	assert.Equal(t, []string{"jquery"}, packageNames(t, packages.NewGitRefSource(dir, "first")))
+
+	_, err := packages.NewGitRefSource(dir, "unknown").Packages(context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cdnjs/tools/packages"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGitHub serves a packages zip with an ETag, and counts the
+// requests and the downloads of the zip.
+type fakeGitHub struct {
+	names     []string
+	requests  int
+	downloads int
+}
+
+func (f *fakeGitHub) etag() string {
+	return fmt.Sprintf(`"%d"`, len(f.names))
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests++
+	if r.Header.Get("If-None-Match") == f.etag() {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	f.downloads++
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range f.names {
+		w, err := zw.Create(fmt.Sprintf("packages-master/packages/%c/%s.json", name[0], name))
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(w, `{"name": "%s"}`, name)
+	}
+	if err := zw.Close(); err != nil {
+		panic(err)
+	}
+
+	w.Header().Set("ETag", f.etag())
+	w.Write(buf.Bytes())
+}
+
+func TestZipSourceCache(t *testing.T) {
+	gh := &fakeGitHub{names: []string{"jquery"}}
+	server := httptest.NewServer(gh)
+	defer server.Close()
+
+	defer func(ttl time.Duration) { packages.ZipCacheTTL = ttl }(packages.ZipCacheTTL)
+	packages.ZipCacheTTL = time.Hour
+
+	url := server.URL + "/ttl.zip"
+	assert.Equal(t, []string{"jquery"}, packageNames(t, packages.NewZipSource(url)))
+	assert.Equal(t, 1, gh.requests)
+
+	// within the TTL, the zip isn't fetched again
+	gh.names = append(gh.names, "vue")
+	assert.Equal(t, []string{"jquery"}, packageNames(t, packages.NewZipSource(url)))
+	assert.Equal(t, 1, gh.requests)
+
+	// after the TTL, the zip is revalidated
+	packages.ZipCacheTTL = 0
+	assert.Equal(t, []string{"jquery", "vue"}, packageNames(t, packages.NewZipSource(url)))
+	assert.Equal(t, 2, gh.requests)
+	assert.Equal(t, 2, gh.downloads)
+
+	// and only downloaded if it has changed
+	assert.Equal(t, []string{"jquery", "vue"}, packageNames(t, packages.NewZipSource(url)))
+	assert.Equal(t, 3, gh.requests)
+	assert.Equal(t, 2, gh.downloads)
+}
+
+func TestZipSourceReload(t *testing.T) {
+	gh := &fakeGitHub{names: []string{"jquery"}}
+	server := httptest.NewServer(gh)
+	defer server.Close()
+
+	defer func(ttl time.Duration) { packages.ZipCacheTTL = ttl }(packages.ZipCacheTTL)
+	packages.ZipCacheTTL = time.Hour
+
+	source := packages.NewZipSource(server.URL + "/reload.zip")
+	assert.Equal(t, []string{"jquery"}, packageNames(t, source))
+	_, err := source.Package(context.Background(), "vue")
+	assert.NotNil(t, err)
+
+	// a long-lived source honours the TTL too
+	gh.names = append(gh.names, "vue")
+	packages.ZipCacheTTL = 0
+	assert.Equal(t, []string{"jquery", "vue"}, packageNames(t, source))
+	pkg, err := source.Package(context.Background(), "vue")
+	assert.Nil(t, err)
+	assert.Equal(t, "vue", *pkg.Name)
+	assert.Equal(t, 3, gh.requests)
+}
+
+func TestZipSourceDiskCache(t *testing.T) {
+	gh := &fakeGitHub{names: []string{"jquery"}}
+	server := httptest.NewServer(gh)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "packages-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(cacheDir string) { packages.PACKAGES_CACHE_DIR = cacheDir }(packages.PACKAGES_CACHE_DIR)
+	packages.PACKAGES_CACHE_DIR = dir
+
+	assert.Equal(t, []string{"jquery"}, packageNames(t, packages.NewZipSource(server.URL+"/disk.zip")))
+	assert.Equal(t, 1, gh.downloads)
+
+	// the zip and its ETag are kept for other processes
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+	for _, file := range files {
+		if path.Ext(file.Name()) == ".etag" {
+			etag, err := ioutil.ReadFile(path.Join(dir, file.Name()))
+			assert.Nil(t, err)
+			assert.Equal(t, gh.etag(), string(etag))
+		}
+	}
+}
+
+func TestGitHubSourceAtInvalidCommit(t *testing.T) {
+	_, err := packages.NewGitHubSourceAt("master").Packages(context.Background())
+	assert.NotNil(t, err)
+}
+
+func TestZipSourceError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := packages.NewZipSource(server.URL + "/missing.zip").Packages(context.Background())
+	assert.NotNil(t, err)
+}
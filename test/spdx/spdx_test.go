@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cdnjs/tools/spdx"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		"MIT":                           "MIT",
+		"mit":                           "MIT",
+		"(MIT)":                         "MIT",
+		"Apache-2.0 OR MIT":             "Apache-2.0 OR MIT",
+		"(MIT OR Apache-2.0)":           "Apache-2.0 OR MIT",
+		"MIT or (ISC or 0BSD)":          "0BSD OR ISC OR MIT",
+		"MIT AND (ISC OR BSD-3-Clause)": "(BSD-3-Clause OR ISC) AND MIT",
+		"MIT OR ISC AND BSD-3-Clause":   "(BSD-3-Clause AND ISC) OR MIT",
+		"GPL-2.0+":                      "GPL-2.0+",
+		"GPL-2.0-only WITH Classpath-exception-2.0": "GPL-2.0-only WITH Classpath-exception-2.0",
+		"LicenseRef-Custom":                         "LicenseRef-Custom",
+	}
+	for expr, canonical := range cases {
+		e, err := spdx.Parse(expr)
+		if assert.Nil(t, err, expr) {
+			assert.Equal(t, canonical, e.String(), expr)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                     "empty license expression",
+		"MIT OR":               "unexpected end of expression",
+		"(MIT OR ISC":          "missing `)`",
+		"MIT ISC":              "unexpected `ISC`",
+		"OR MIT":               "unexpected `OR`",
+		"MIT Or ISC":           "unexpected `Or`",
+		"BSD":                  "unknown license `BSD`",
+		"MIT WITH Unknown-1.0": "unknown license exception `Unknown-1.0`",
+	}
+	for expr, msg := range cases {
+		_, err := spdx.Parse(expr)
+		if assert.NotNil(t, err, expr) {
+			assert.Equal(t, msg, err.Error(), expr)
+		}
+	}
+}
+
+func TestOSIApproved(t *testing.T) {
+	cases := map[string]bool{
+		"MIT":                    true,
+		"CC-BY-4.0":              false,
+		"MIT OR CC-BY-4.0":       true,
+		"MIT AND CC-BY-4.0":      false,
+		"GPL-2.0+":               true,
+		"LicenseRef-Custom":      false,
+		"(ISC OR WTFPL) AND MIT": true,
+	}
+	for expr, approved := range cases {
+		e, err := spdx.Parse(expr)
+		if assert.Nil(t, err, expr) {
+			assert.Equal(t, approved, e.OSIApproved(), expr)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, err := spdx.Parse("(MIT OR Apache-2.0)")
+	assert.Nil(t, err)
+	b, err := spdx.Parse("apache-2.0 or mit")
+	assert.Nil(t, err)
+	c, err := spdx.Parse("MIT AND Apache-2.0")
+	assert.Nil(t, err)
+
+	assert.True(t, a.Equal(b))
+	assert.False(t, a.Equal(c))
+	assert.Equal(t, []string{"Apache-2.0", "MIT"}, b.Licenses())
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cdnjs/tools/util"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI serves the path of the request, and counts the requests.
+type fakeAPI struct {
+	requests int32
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&f.requests, 1)
+	switch r.URL.Path {
+	case "/missing":
+		w.WriteHeader(http.StatusNotFound)
+	case "/limited":
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1600000000")
+		w.WriteHeader(http.StatusForbidden)
+	case "/too-many":
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	case "/flaky":
+		if n == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+	case "/broken":
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	case "/private":
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+	fmt.Fprint(w, r.URL.Path)
+}
+
+func TestCachedGetSharesResponses(t *testing.T) {
+	api := &fakeAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := util.CachedGet(server.URL+"/shared", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "/shared", string(resp.Body))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), api.requests)
+
+	// not found responses are cached too
+	for i := 0; i < 2; i++ {
+		resp, err := util.CachedGet(server.URL+"/missing", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+	assert.Equal(t, int32(2), api.requests)
+}
+
+func TestCachedGetErrors(t *testing.T) {
+	api := &fakeAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, err := util.CachedGet(server.URL+"/limited", nil)
+	assert.Equal(t, util.RateLimitError{URL: server.URL + "/limited", Reset: time.Unix(1600000000, 0)}, err)
+	assert.Equal(t, "rate limit hit for "+server.URL+"/limited, resets at 2020-09-13T12:26:40Z", err.Error())
+
+	_, err = util.CachedGet(server.URL+"/too-many", nil)
+	_, ok := err.(util.RateLimitError)
+	assert.True(t, ok)
+
+	_, err = util.CachedGet(server.URL+"/broken", nil)
+	assert.NotNil(t, err)
+	_, ok = errors.Cause(err).(util.RateLimitError)
+	assert.False(t, ok)
+}
+
+func TestCachedGetRetriesErrors(t *testing.T) {
+	api := &fakeAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	_, err := util.CachedGet(server.URL+"/flaky", nil)
+	assert.NotNil(t, err)
+
+	resp, err := util.CachedGet(server.URL+"/flaky", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "/flaky", string(resp.Body))
+	assert.Equal(t, int32(2), api.requests)
+}
+
+func TestCachedGetDiskCache(t *testing.T) {
+	api := &fakeAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "http-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	util.HTTP_CACHE_DIR = dir
+	defer func() { util.HTTP_CACHE_DIR = "" }()
+
+	cached := func(url string, resp util.CachedResponse) {
+		bytes, err := json.Marshal(resp)
+		assert.Nil(t, err)
+		file := path.Join(dir, fmt.Sprintf("%x.json", sha1.Sum([]byte(url))))
+		assert.Nil(t, ioutil.WriteFile(file, bytes, 0644))
+	}
+
+	// a response of a previous run is used instead of the failing API
+	cached(server.URL+"/broken", util.CachedResponse{
+		StatusCode: http.StatusOK,
+		Body:       []byte("from disk"),
+		FetchedAt:  time.Now(),
+	})
+	resp, err := util.CachedGet(server.URL+"/broken", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "from disk", string(resp.Body))
+	assert.Equal(t, int32(0), api.requests)
+
+	// unless it has expired
+	cached(server.URL+"/expired", util.CachedResponse{
+		StatusCode: http.StatusOK,
+		Body:       []byte("from disk"),
+		FetchedAt:  time.Now().Add(-2 * util.HTTPCacheTTL),
+	})
+	resp, err = util.CachedGet(server.URL+"/expired", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "/expired", string(resp.Body))
+	assert.Equal(t, int32(1), api.requests)
+
+	// and fetched responses are persisted
+	_, err = util.CachedGet(server.URL+"/persisted", nil)
+	assert.Nil(t, err)
+	bytes, err := ioutil.ReadFile(path.Join(dir, fmt.Sprintf("%x.json", sha1.Sum([]byte(server.URL+"/persisted")))))
+	assert.Nil(t, err)
+	var persisted util.CachedResponse
+	assert.Nil(t, json.Unmarshal(bytes, &persisted))
+	assert.Equal(t, "/persisted", string(persisted.Body))
+}
+
+func TestCachedGetCredentials(t *testing.T) {
+	api := &fakeAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "http-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	util.HTTP_CACHE_DIR = dir
+	defer func() { util.HTTP_CACHE_DIR = "" }()
+
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer secret")
+	resp, err := util.CachedGet(server.URL+"/private", header)
+	assert.Nil(t, err)
+	assert.Equal(t, "/private", string(resp.Body))
+
+	// responses to authenticated requests aren't shared, in memory or on disk
+	_, err = util.CachedGet(server.URL+"/private", nil)
+	assert.NotNil(t, err)
+
+	header.Set("Authorization", "Bearer other")
+	_, err = util.CachedGet(server.URL+"/private", header)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), api.requests)
+
+	// and the credentials aren't written to the disk cache
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+	for _, f := range files {
+		bytes, err := ioutil.ReadFile(path.Join(dir, f.Name()))
+		assert.Nil(t, err)
+		assert.NotContains(t, string(bytes), "secret")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cdnjs/tools/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const positionJSON = `{
+  "name": "a-happy-tyler",
+  "keywords": ["tyler", "happy"],
+  "description": "Tyler is \"happy\" \/ ünicode",
+  "autoupdate": {
+    "source": "npm",
+    "fileMap": [
+      {
+        "basePath": "",
+        "files": [
+          "*",
+          ""
+        ]
+      }
+    ]
+  },
+  "empty": {},
+  "list": [[], {"a.b": null}, 1.5e3, true]
+}`
+
+func TestJSONFieldPosition(t *testing.T) {
+	cases := map[string]util.FilePosition{
+		"(root)":                       {Line: 1, Col: 1},
+		"name":                         {Line: 2, Col: 3},
+		"keywords.1":                   {Line: 3, Col: 25},
+		"autoupdate":                   {Line: 5, Col: 3},
+		"autoupdate.source":            {Line: 6, Col: 5},
+		"autoupdate.fileMap.0":         {Line: 8, Col: 7},
+		"autoupdate.fileMap.0.files":   {Line: 10, Col: 9},
+		"autoupdate.fileMap.0.files.1": {Line: 12, Col: 11},
+		"list.3":                       {Line: 18, Col: 38},
+	}
+
+	for field, expected := range cases {
+		pos, ok := util.JSONFieldPosition([]byte(positionJSON), field)
+		assert.True(t, ok, field)
+		assert.Equal(t, expected, pos, field)
+	}
+
+	for _, field := range []string{"version", "autoupdate.fileMap.1", "keywords.name", "empty.a"} {
+		_, ok := util.JSONFieldPosition([]byte(positionJSON), field)
+		assert.False(t, ok, field)
+	}
+
+	_, ok := util.JSONFieldPosition([]byte(`{"name": `), "name")
+	assert.False(t, ok)
+}
+
+func TestPositionOf(t *testing.T) {
+	src := []byte("{\n  \"ü\": 1\n}")
+	assert.Equal(t, util.FilePosition{Line: 1, Col: 1}, util.PositionOf(src, 0))
+	assert.Equal(t, util.FilePosition{Line: 2, Col: 8}, util.PositionOf(src, 10))
+	assert.Equal(t, util.FilePosition{Line: 3, Col: 2}, util.PositionOf(src, len(src)))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/version"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"10.0", "9.0", 1},
+		{"v1.2", "1.1.9", 1},
+		{"1.0", "1.0.0", -1}, // equivalent, ordered by string
+		{"2.0.0.1", "2.0.0", 1},
+		{"2.0.0.1", "2.0.1", -1},
+		{"1.02.3", "1.2.4", -1},
+		{"1.2.3-beta", "1.2.3", -1},
+		{"1.2.3-beta.2", "1.2.3-beta.10", -1},
+		{"1.2.3-beta", "1.2.3-beta.1", -1},
+		{"1.2.3-1", "1.2.3-alpha", -1},
+		{"1.2.3-rc.1", "1.2.2", 1},
+		{"1.0.0+build.1", "1.0.0-rc.1", 1},
+		{"nightly", "0.0.1", -1},
+		{"r10", "r9", 1},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(fmt.Sprintf("%s vs %s", tc.a, tc.b), func(t *testing.T) {
+			assert.Equal(t, tc.expected, version.Compare(tc.a, tc.b))
+			assert.Equal(t, -tc.expected, version.Compare(tc.b, tc.a))
+		})
+	}
+}
+
+func TestByVersionString(t *testing.T) {
+	versions := []string{"9.0", "1.2.3-beta", "nightly", "10.0", "v1.2", "1.2.3", "2.0.0.1"}
+	sort.Sort(version.ByVersionString(versions))
+
+	assert.Equal(t, []string{"10.0", "9.0", "2.0.0.1", "1.2.3", "1.2.3-beta", "v1.2", "nightly"}, versions)
+}
+
+func TestByVersionAsset(t *testing.T) {
+	assets := []packages.Asset{{Version: "1.9.0"}, {Version: "1.10.0"}, {Version: "1.10.0-rc.1"}}
+	sort.Sort(version.ByVersionAsset(assets))
+
+	assert.Equal(t, []packages.Asset{{Version: "1.10.0"}, {Version: "1.10.0-rc.1"}, {Version: "1.9.0"}}, assets)
+}
+
+func TestGetLatestStableVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		versions []string
+		expected *string
+	}{
+		{
+			name: "no versions",
+		},
+		{
+			name:     "numeric segments",
+			versions: []string{"9.0", "10.0", "10.0.1-rc.1"},
+			expected: strPtr("10.0"),
+		},
+		{
+			name:     "loose versions",
+			versions: []string{"v1.2", "1.1.0", "1.2-beta"},
+			expected: strPtr("v1.2"),
+		},
+		{
+			name:     "only prereleases",
+			versions: []string{"1.0.0-beta.2", "1.0.0-beta.10"},
+			expected: strPtr("1.0.0-beta.10"),
+		},
+		{
+			name:     "not versions",
+			versions: []string{"build9", "build10"},
+			expected: strPtr("build10"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, version.GetLatestStableVersion(tc.versions))
+		})
+	}
+}
+
+func TestByDateSameDate(t *testing.T) {
+	date := time.Unix(0, 0)
+	versions := []version.Version{
+		{Version: "1.9.0", Date: date},
+		{Version: "1.10.0", Date: date},
+		{Version: "2.0.0", Date: date.Add(time.Hour)},
+	}
+	sort.Sort(version.ByDate(versions))
+
+	assert.Equal(t, []string{"2.0.0", "1.10.0", "1.9.0"}, versionNames(versions))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cdnjs/tools/version"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var tarball = []byte("a tarball")
+
+func digest(sum []byte) string {
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+func TestVerifyIntegrity(t *testing.T) {
+	sha512Sum := sha512.Sum512(tarball)
+	sha256Sum := sha256.Sum256(tarball)
+	sha1Sum := sha1.Sum(tarball)
+	other := sha512.Sum512([]byte("another tarball"))
+
+	valid := []string{
+		"sha512-" + digest(sha512Sum[:]),
+		"sha256-" + digest(sha256Sum[:]),
+		"sha1-" + digest(sha1Sum[:]),
+		// the strongest hash is used
+		"sha1-" + digest(other[:20]) + " sha512-" + digest(sha512Sum[:]),
+		// any digest of the strongest hash can match
+		"sha512-" + digest(other[:]) + " sha512-" + digest(sha512Sum[:]) + "?opt",
+	}
+	for _, integrity := range valid {
+		assert.Nil(t, version.VerifyIntegrity(tarball, integrity), integrity)
+	}
+
+	err := version.VerifyIntegrity(tarball, "sha512-"+digest(other[:])+" sha1-"+digest(sha1Sum[:]))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "integrity mismatch: expected sha512-"+digest(other[:])+", got sha512-"+digest(sha512Sum[:]), err.Error())
+	}
+
+	err = version.VerifyIntegrity(tarball, "md5-abc")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "unsupported integrity `md5-abc`", err.Error())
+	}
+}
+
+func TestVersionVerify(t *testing.T) {
+	sha512Sum := sha512.Sum512(tarball)
+	sha1Sum := sha1.Sum(tarball)
+	shasum := hex.EncodeToString(sha1Sum[:])
+
+	// git versions aren't verified
+	v := version.Version{Version: "1.0.0"}
+	assert.Nil(t, v.Verify(tarball))
+	assert.Equal(t, "", v.Digest())
+
+	v = version.Version{Version: "1.0.0", Integrity: "sha512-" + digest(sha512Sum[:]), Shasum: shasum}
+	assert.Nil(t, v.Verify(tarball))
+	assert.NotNil(t, v.Verify([]byte("a tampered tarball")))
+	assert.Equal(t, v.Integrity, v.Digest())
+
+	// old versions only have a shasum
+	v = version.Version{Version: "0.1.0", Shasum: shasum}
+	assert.Nil(t, v.Verify(tarball))
+	assert.Equal(t, "sha1-"+digest(sha1Sum[:]), v.Digest())
+
+	err := v.Verify([]byte("a tampered tarball"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "shasum mismatch: expected "+shasum)
+	}
+}
+
+func TestDownloadTarVerifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.tgz":
+			w.Write([]byte("a tampered tarball"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	sha512Sum := sha512.Sum512(tarball)
+	integrity := "sha512-" + digest(sha512Sum[:])
+
+	// tampered tarballs won't verify on later downloads either
+	_, err := version.DownloadTar(context.Background(), version.Version{Version: "1.0.0", Tarball: server.URL + "/a.tgz", Integrity: integrity})
+	if assert.NotNil(t, err) {
+		_, ok := errors.Cause(err).(version.VerifyError)
+		assert.True(t, ok, err.Error())
+	}
+
+	// error pages aren't verified
+	_, err = version.DownloadTar(context.Background(), version.Version{Version: "1.0.0", Tarball: server.URL + "/missing.tgz", Integrity: integrity})
+	if assert.NotNil(t, err) {
+		_, ok := errors.Cause(err).(version.VerifyError)
+		assert.False(t, ok, err.Error())
+		assert.Contains(t, err.Error(), "404 Not Found")
+	}
+}
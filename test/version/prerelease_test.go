@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/version"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func versionNames(versions []version.Version) []string {
+	names := make([]string, 0)
+	for _, v := range versions {
+		names = append(names, v.Version)
+	}
+	return names
+}
+
+func createVersions(names ...string) []version.Version {
+	versions := make([]version.Version, 0)
+	for i, name := range names {
+		versions = append(versions, version.Version{
+			Version: name,
+			Date:    time.Unix(int64(i), 0),
+		})
+	}
+	return versions
+}
+
+func TestFilterPrereleases(t *testing.T) {
+	target := "lib"
+	stable, latest := packages.PrereleaseStable, packages.PrereleaseLatest
+
+	cases := []struct {
+		name     string
+		policy   *string
+		versions []version.Version
+		expected []string
+	}{
+		{
+			name:     "all by default",
+			versions: createVersions("1.0.0", "2.0.0-rc.1", "2.0.0-canary.2"),
+			expected: []string{"1.0.0", "2.0.0-rc.1", "2.0.0-canary.2"},
+		},
+		{
+			name:     "stable",
+			policy:   &stable,
+			versions: createVersions("1.0.0", "1.1.0-alpha", "v1.1.0", "nightly"),
+			expected: []string{"1.0.0", "v1.1.0", "nightly"},
+		},
+		{
+			name:     "latest prerelease",
+			policy:   &latest,
+			versions: createVersions("1.0.0", "2.0.0-beta.1", "2.0.0-rc.1", "2.0.0-beta.2"),
+			expected: []string{"1.0.0", "2.0.0-rc.1"},
+		},
+		{
+			name:     "latest prerelease older than stable",
+			policy:   &latest,
+			versions: createVersions("2.0.0-rc.1", "2.0.0", "1.5.0"),
+			expected: []string{"2.0.0", "1.5.0"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			config := &packages.Autoupdate{
+				Target:     &target,
+				Prerelease: tc.policy,
+			}
+			assert.Equal(t, tc.expected, versionNames(version.FilterPrereleases(config, tc.versions)))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cdnjs/tools/packages"
+	"github.com/cdnjs/tools/version"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type RangeTestCase struct {
+	rng     string
+	in      []string
+	notIn   []string
+	invalid bool
+}
+
+func TestRange(t *testing.T) {
+	cases := []RangeTestCase{
+		{rng: ">=2.0.0 <4", in: []string{"2.0.0", "3.9.9", "v3.1"}, notIn: []string{"1.9.9", "4.0.0", "4.0.0-rc.1"}},
+		{rng: "^5", in: []string{"5.0.0", "5.99.1"}, notIn: []string{"4.9.9", "6.0.0", "5.1.0-beta"}},
+		{rng: "^0.2.3", in: []string{"0.2.3", "0.2.9"}, notIn: []string{"0.3.0", "0.2.2"}},
+		{rng: "^0.0.3", in: []string{"0.0.3"}, notIn: []string{"0.0.4"}},
+		{rng: "~1.2", in: []string{"1.2.0", "1.2.9"}, notIn: []string{"1.3.0", "1.1.9"}},
+		{rng: "~1", in: []string{"1.0.0", "1.9.0"}, notIn: []string{"2.0.0"}},
+		{rng: "1.x || >=3.1.0", in: []string{"1.0.0", "1.5.2", "3.1.0", "10.0.0"}, notIn: []string{"2.0.0", "3.0.9"}},
+		{rng: "1.2.3 - 2.3", in: []string{"1.2.3", "2.3.9"}, notIn: []string{"1.2.2", "2.4.0"}},
+		{rng: "> 1.2", in: []string{"1.3.0"}, notIn: []string{"1.2.9"}},
+		{rng: "<=1.2", in: []string{"1.2.9"}, notIn: []string{"1.3.0"}},
+		{rng: "*", in: []string{"0.0.1", "100.0.0"}, notIn: []string{"1.0.0-alpha", "not-a-version"}},
+		{rng: ">=1.0.0-beta.2 <2", in: []string{"1.0.0-beta.2", "1.0.0-rc.1", "1.5.0"}, notIn: []string{"1.0.0-beta.1", "1.5.0-beta"}},
+		{rng: "=1.2.3", in: []string{"1.2.3", "v1.2.3"}, notIn: []string{"1.2.4"}},
+		{rng: ">=abc", invalid: true},
+		{rng: "1.2.3 <", invalid: true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.rng, func(t *testing.T) {
+			r, err := version.ParseRange(tc.rng)
+			if tc.invalid {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			for _, v := range tc.in {
+				assert.True(t, r.Contains(v), "%s should satisfy %s", v, tc.rng)
+			}
+			for _, v := range tc.notIn {
+				assert.False(t, r.Contains(v), "%s should not satisfy %s", v, tc.rng)
+			}
+		})
+	}
+}
+
+func TestIsVersionIgnoredRange(t *testing.T) {
+	versionRange := "^2"
+	config := &packages.Autoupdate{
+		IgnoreVersions: []string{"2.1.*"},
+		VersionRange:   &versionRange,
+	}
+
+	assert.False(t, version.IsVersionIgnored(config, "2.0.0"))
+	assert.True(t, version.IsVersionIgnored(config, "2.1.0"))
+	assert.True(t, version.IsVersionIgnored(config, "3.0.0"))
+
+	// no version is listed for an invalid range
+	versionRange = ">=abc"
+	assert.True(t, version.IsVersionIgnored(config, "2.0.0"))
+}
+
+func TestRangeContainsPrerelease(t *testing.T) {
+	r, err := version.ParseRange("^2.0.0")
+	assert.Nil(t, err)
+
+	assert.False(t, r.Contains("2.1.0-beta.1"))
+	assert.True(t, r.ContainsPrerelease("2.1.0-beta.1"))
+	assert.True(t, r.ContainsPrerelease("2.1.0"))
+	// prereleases of the bounds, like npm
+	assert.False(t, r.ContainsPrerelease("3.0.0-rc.1"))
+	assert.False(t, r.ContainsPrerelease("2.0.0-rc.1"))
+	assert.False(t, r.ContainsPrerelease("1.9.0-rc.1"))
+}
+
+func TestVersionRangePrereleasePolicy(t *testing.T) {
+	versionRange := "^2.0.0"
+	all, latest, stable := packages.PrereleaseAll, packages.PrereleaseLatest, packages.PrereleaseStable
+	versions := createVersions("1.9.0", "2.0.0", "2.1.0-beta.1", "2.1.0-beta.2", "3.0.0-rc.1")
+
+	// the versions listed by the npm and git clients
+	list := func(policy *string) []string {
+		target := "lib"
+		config := &packages.Autoupdate{Target: &target, VersionRange: &versionRange, Prerelease: policy}
+		var listed []version.Version
+		for _, v := range versions {
+			if !version.IsVersionIgnored(config, v.Version) {
+				listed = append(listed, v)
+			}
+		}
+		return versionNames(version.FilterPrereleases(config, listed))
+	}
+
+	// the prerelease policy selects the prereleases within the range
+	assert.Equal(t, []string{"2.0.0", "2.1.0-beta.1", "2.1.0-beta.2"}, list(&all))
+	assert.Equal(t, []string{"2.0.0", "2.1.0-beta.1", "2.1.0-beta.2"}, list(nil))
+	assert.Equal(t, []string{"2.0.0", "2.1.0-beta.2"}, list(&latest))
+	assert.Equal(t, []string{"2.0.0"}, list(&stable))
+}
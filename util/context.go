@@ -38,6 +38,10 @@ const (
 
 	// Info is the LogFunc that is called when outputting an info.
 	Info
+
+	// Position is the key for the FilePosition in the LoggerPrefix file
+	// that is being logged about. It defaults to the first line.
+	Position
 )
 
 // ContextWithEntries creates a context with a variadic number of key-value
@@ -1,19 +1,18 @@
 package util
 
 import (
-	"bytes"
 	"context"
-	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/cdnjs/tools/glob"
+
 	"github.com/karrick/godirwalk"
 )
 
-// ListFilesGlob is the legacy, slower version that uses
-// the node glob tool found here: https://github.com/cdnjs/glob
+// ListFilesGlob lists the files in base matching a glob pattern, with the
+// same semantics as the node glob tool found here: https://github.com/cdnjs/glob
 func ListFilesGlob(ctx context.Context, base string, pattern string) ([]string, error) {
 	list := make([]string, 0)
 
@@ -28,24 +27,7 @@ func ListFilesGlob(ctx context.Context, base string, pattern string) ([]string,
 		return list, nil
 	}
 
-	cmd := exec.Command("/glob/index.js", pattern)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-	cmd.Dir = base
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("%s: %s\n", err, out.String())
-		return list, err
-	}
-
-	for _, line := range strings.Split(out.String(), "\n") {
-		if strings.Trim(line, " ") != "" {
-			list = append(list, line)
-		}
-
-	}
-	return list, nil
+	return glob.List(base, pattern), nil
 }
 
 // Determines if a file path contains a hidden file or directory.
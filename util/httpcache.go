@@ -0,0 +1,198 @@
+package util
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Directory in which the responses of CachedGet are kept across
+	// processes. If empty, responses are only cached in memory.
+	HTTP_CACHE_DIR = os.Getenv("HTTP_CACHE_DIR")
+
+	// HTTPCacheTTL is how long the responses kept in HTTP_CACHE_DIR are used.
+	HTTPCacheTTL = 24 * time.Hour
+)
+
+// CachedResponse is a response of CachedGet.
+type CachedResponse struct {
+	StatusCode int       `json:"statusCode"`
+	Body       []byte    `json:"body"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+}
+
+// RateLimitError is returned when the rate limit of an API is hit.
+type RateLimitError struct {
+	URL string
+	// Reset is when requests are allowed again, if known.
+	Reset time.Time
+}
+
+// Error is used to satisfy the error interface.
+func (e RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("rate limit hit for %s", e.URL)
+	}
+	return fmt.Sprintf("rate limit hit for %s, resets at %s", e.URL, e.Reset.UTC().Format(time.RFC3339))
+}
+
+// httpCacheEntry is the response to a URL, shared by concurrent requests.
+type httpCacheEntry struct {
+	once sync.Once
+	resp *CachedResponse
+	err  error
+}
+
+var (
+	httpCacheMu sync.Mutex
+	httpCache   = make(map[string]*httpCacheEntry)
+)
+
+// CachedGet fetches a URL once, sharing the response with concurrent and later
+// requests for the same format (Accept header) and with the same credentials
+// (Authorization header). OK and not found responses are cached, in memory and
+// in HTTP_CACHE_DIR if set. Other responses are errors, a RateLimitError if a
+// rate limit was hit, which aren't cached.
+func CachedGet(url string, header http.Header) (*CachedResponse, error) {
+	key := url
+	if accept := header.Get("Accept"); accept != "" {
+		key += " " + accept
+	}
+	// responses to authenticated requests aren't shared with other
+	// requests, and the credentials are hashed since keys are logged
+	if auth := header.Get("Authorization"); auth != "" {
+		key += fmt.Sprintf(" auth:%x", sha256.Sum256([]byte(auth)))
+	}
+
+	httpCacheMu.Lock()
+	entry, ok := httpCache[key]
+	if !ok {
+		entry = &httpCacheEntry{}
+		httpCache[key] = entry
+	}
+	httpCacheMu.Unlock()
+
+	entry.once.Do(func() {
+		if entry.resp = readHTTPDiskCache(key); entry.resp != nil {
+			return
+		}
+		entry.resp, entry.err = fetch(url, header)
+		if entry.err != nil {
+			// later requests retry
+			httpCacheMu.Lock()
+			delete(httpCache, key)
+			httpCacheMu.Unlock()
+			return
+		}
+		writeHTTPDiskCache(key, entry.resp)
+	})
+	return entry.resp, entry.err
+}
+
+func fetch(url string, header http.Header) (*CachedResponse, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create request")
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not fetch %s", url)
+	}
+	defer resp.Body.Close()
+
+	if e, ok := rateLimitError(url, resp); ok {
+		return nil, e
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return nil, errors.Errorf("could not fetch %s: %s", url, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", url)
+	}
+	return &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		FetchedAt:  time.Now(),
+	}, nil
+}
+
+// Returns the rate limit error of a response, as sent by the GitHub
+// API (403 with no remaining requests) or by the npm API (429).
+func rateLimitError(url string, resp *http.Response) (RateLimitError, bool) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+	default:
+		return RateLimitError{}, false
+	}
+
+	e := RateLimitError{URL: url}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		e.Reset = time.Unix(reset, 0)
+	} else if after, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.Reset = time.Now().Add(time.Duration(after) * time.Second)
+	}
+	return e, true
+}
+
+// Returns the path in the disk cache of the response to a URL,
+// followed by its format if any.
+func httpDiskCachePath(key string) string {
+	return filepath.Join(HTTP_CACHE_DIR, fmt.Sprintf("%x.json", sha1.Sum([]byte(key))))
+}
+
+// Reads a response from the disk cache, unless it is older than HTTPCacheTTL.
+func readHTTPDiskCache(key string) *CachedResponse {
+	if HTTP_CACHE_DIR == "" {
+		return nil
+	}
+
+	bytes, err := ioutil.ReadFile(httpDiskCachePath(key))
+	if err != nil {
+		return nil
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(bytes, &resp); err != nil {
+		log.Printf("ignoring cached response to %s: %s\n", key, err)
+		return nil
+	}
+	if time.Since(resp.FetchedAt) > HTTPCacheTTL {
+		return nil
+	}
+	return &resp
+}
+
+// Writes a response to the disk cache, ignoring failures.
+func writeHTTPDiskCache(key string, resp *CachedResponse) {
+	if HTTP_CACHE_DIR == "" {
+		return
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err == nil {
+		err = os.MkdirAll(HTTP_CACHE_DIR, os.ModePerm)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(httpDiskCachePath(key), bytes, 0644)
+	}
+	if err != nil {
+		log.Printf("could not cache response to %s: %s\n", key, err)
+	}
+}
@@ -135,7 +135,11 @@ const (
 func checkerLogf(ctx context.Context, logType checkerLogType, format string, v ...interface{}) {
 	if logger, ok := ctx.Value(Logger).(*log.Logger); ok && logger != nil {
 		if prefix, ok := ctx.Value(LoggerPrefix).(string); ok {
-			logger.Printf("::%s file=%s,line=1,col=1::%s\n", logType, prefix, escapeGitHub(fmt.Sprintf(format, v...)))
+			pos, ok := ctx.Value(Position).(FilePosition)
+			if !ok {
+				pos = FilePosition{Line: 1, Col: 1}
+			}
+			logger.Printf("::%s file=%s,line=%d,col=%d::%s\n", logType, prefix, pos.Line, pos.Col, escapeGitHub(fmt.Sprintf(format, v...)))
 		} else {
 			panic("logger prefix does not exist")
 		}
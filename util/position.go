@@ -0,0 +1,167 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FilePosition is a 1-based line and column in a file.
+type FilePosition struct {
+	Line int
+	Col  int
+}
+
+// PositionOf returns the position of a byte offset in a file,
+// the column counting characters rather than bytes.
+func PositionOf(src []byte, offset int) FilePosition {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return FilePosition{
+		Line: bytes.Count(src[:offset], []byte("\n")) + 1,
+		Col:  utf8.RuneCount(src[lineStart:offset]) + 1,
+	}
+}
+
+// JSONFieldPosition returns the position in a JSON document of a field,
+// as named by gojsonschema (ex. `autoupdate.fileMap.0.files`, or `(root)`).
+// The position of an object property is the one of its key, and the position
+// of an array item the one of the item. If the field doesn't exist, or the
+// document isn't valid JSON, false is returned.
+func JSONFieldPosition(src []byte, field string) (FilePosition, bool) {
+	var path []string
+	if field != "(root)" && field != "" {
+		path = strings.Split(field, ".")
+	}
+
+	s := &jsonScanner{src: src, target: path, found: -1}
+	s.skipSpace()
+	if !s.value(nil, s.pos) || s.found < 0 {
+		return FilePosition{}, false
+	}
+	return PositionOf(src, s.found), true
+}
+
+// jsonScanner walks a JSON document, looking for the offset of a value.
+type jsonScanner struct {
+	src    []byte
+	pos    int
+	target []string
+	found  int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.src) && strings.IndexByte(" \t\r\n", s.src[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+func (s *jsonScanner) consume(c byte) bool {
+	s.skipSpace()
+	if s.pos < len(s.src) && s.src[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *jsonScanner) matches(path []string) bool {
+	if len(path) != len(s.target) {
+		return false
+	}
+	for i := range path {
+		if path[i] != s.target[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Scans the value at the current position, reporting offset
+// if it is the target, and returns false on invalid JSON.
+func (s *jsonScanner) value(path []string, offset int) bool {
+	if s.found < 0 && s.matches(path) {
+		s.found = offset
+	}
+
+	s.skipSpace()
+	if s.pos >= len(s.src) {
+		return false
+	}
+	switch s.src[s.pos] {
+	case '{':
+		s.pos++
+		if s.consume('}') {
+			return true
+		}
+		for {
+			s.skipSpace()
+			keyOffset := s.pos
+			key, ok := s.string()
+			if !ok || !s.consume(':') {
+				return false
+			}
+			s.skipSpace()
+			if !s.value(append(path[:len(path):len(path)], key), keyOffset) {
+				return false
+			}
+			if s.consume('}') {
+				return true
+			}
+			if !s.consume(',') {
+				return false
+			}
+		}
+	case '[':
+		s.pos++
+		if s.consume(']') {
+			return true
+		}
+		for i := 0; ; i++ {
+			s.skipSpace()
+			if !s.value(append(path[:len(path):len(path)], strconv.Itoa(i)), s.pos) {
+				return false
+			}
+			if s.consume(']') {
+				return true
+			}
+			if !s.consume(',') {
+				return false
+			}
+		}
+	case '"':
+		_, ok := s.string()
+		return ok
+	default:
+		// number, true, false or null
+		start := s.pos
+		for s.pos < len(s.src) && strings.IndexByte(",}] \t\r\n", s.src[s.pos]) < 0 {
+			s.pos++
+		}
+		return s.pos > start
+	}
+}
+
+// Scans a string at the current position.
+func (s *jsonScanner) string() (string, bool) {
+	start := s.pos
+	if s.pos >= len(s.src) || s.src[s.pos] != '"' {
+		return "", false
+	}
+	for s.pos++; s.pos < len(s.src); s.pos++ {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos++
+		case '"':
+			s.pos++
+			var str string
+			err := json.Unmarshal(s.src[start:s.pos], &str)
+			return str, err == nil
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,141 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	looseRegex  = regexp.MustCompile(`^[v=]?([0-9]+(?:\.[0-9]+)*)(?:-?([0-9A-Za-z][0-9A-Za-z.-]*))?(?:\+[0-9A-Za-z.-]*)?$`)
+	digitsRegex = regexp.MustCompile(`[0-9]+`)
+)
+
+// loose is a version parsed tolerantly: any number of numeric
+// segments, an optional prerelease and an ignored build metadata.
+type loose struct {
+	segments []string
+	pre      []string
+	// whether the version looks like a version at all
+	ok bool
+}
+
+func parseLoose(version string) loose {
+	version = strings.TrimSpace(version)
+	m := looseRegex.FindStringSubmatch(version)
+	if m == nil {
+		// not a version, only its numbers are compared
+		return loose{segments: digitsRegex.FindAllString(version, -1)}
+	}
+
+	l := loose{segments: strings.Split(m[1], "."), ok: true}
+	if m[2] != "" {
+		l.pre = strings.Split(m[2], ".")
+	}
+	return l
+}
+
+// Compares two strings of digits numerically, ignoring leading zeros.
+func compareDigits(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// Compares numeric segments, missing segments being zero.
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		left, right := "0", "0"
+		if i < len(a) {
+			left = a[i]
+		}
+		if i < len(b) {
+			right = b[i]
+		}
+		if c := compareDigits(left, right); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compares prerelease identifiers as semver does, a version
+// without prerelease being greater than one with.
+func comparePrereleases(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		leftNum, rightNum := isDigits(a[i]), isDigits(b[i])
+		var c int
+		switch {
+		case leftNum && rightNum:
+			c = compareDigits(a[i], b[i])
+		case leftNum:
+			c = -1
+		case rightNum:
+			c = 1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	// a larger set of identifiers has a higher precedence
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// Compare compares two versions, returning -1, 0 or 1. It is tolerant of
+// versions which are not strictly semver, such as `v1.2`, `1.0`, `2.0.0.1`
+// or `1.02.3-beta`, by comparing their numeric segments one by one.
+//
+// Strings which do not look like versions at all (ex. `nightly`) are lower
+// than any version, and are ordered by the numbers they contain. Versions
+// which are equivalent once normalized (ex. `1.0` and `v1.0.0`) are ordered
+// by their string, so that Compare is a total order.
+func Compare(a, b string) int {
+	left, right := parseLoose(a), parseLoose(b)
+	if left.ok != right.ok {
+		if left.ok {
+			return 1
+		}
+		return -1
+	}
+
+	if c := compareSegments(left.segments, right.segments); c != 0 {
+		return c
+	}
+	if c := comparePrereleases(left.pre, right.pre); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// IsStable determines if a version looks like a version
+// and is not a prerelease.
+func IsStable(version string) bool {
+	l := parseLoose(version)
+	return l.ok && len(l.pre) == 0
+}
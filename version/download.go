@@ -6,22 +6,37 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/cdnjs/tools/util"
+	"github.com/pkg/errors"
 )
 
-func DownloadTar(ctx context.Context, v Version) bytes.Buffer {
+// DownloadTar downloads the tarball of a version, verifying it against
+// the integrity and the shasum of the version if any. A tarball which
+// doesn't match them is an error, since it was tampered with.
+func DownloadTar(ctx context.Context, v Version) (bytes.Buffer, error) {
+	var buff bytes.Buffer
 	if v.Tarball == "" {
 		panic("no tarball url provided for " + v.Version)
 	}
 	log.Printf("download %s\n", v.Tarball)
 
-	resp, err := http.Get(v.Tarball)
-	util.Check(err)
+	req, err := http.NewRequest("GET", v.Tarball, nil)
+	if err != nil {
+		return buff, errors.Wrap(err, "could not create request")
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return buff, errors.Wrapf(err, "could not download %s", v.Tarball)
+	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return buff, errors.Errorf("could not download %s: %s", v.Tarball, resp.Status)
+	}
 
-	var buff bytes.Buffer
-	_, err = buff.ReadFrom(resp.Body)
-	util.Check(err)
-
-	return buff
+	if _, err := buff.ReadFrom(resp.Body); err != nil {
+		return buff, errors.Wrapf(err, "could not read %s", v.Tarball)
+	}
+	if err := v.Verify(buff.Bytes()); err != nil {
+		return buff, errors.Wrapf(err, "could not verify %s", v.Tarball)
+	}
+	return buff, nil
 }
@@ -0,0 +1,95 @@
+package version
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Hashes of the integrity strings, from the strongest.
+var integrityHashes = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{"sha512", sha512.New},
+	{"sha384", sha512.New384},
+	{"sha256", sha256.New},
+	{"sha1", sha1.New},
+}
+
+// VerifyError is returned when a tarball doesn't match the digests
+// of its version, which means it was tampered with, or when they are
+// in an unsupported format. Downloading the tarball again won't help.
+type VerifyError struct {
+	Reason string
+}
+
+// Error is used to satisfy the error interface.
+func (e VerifyError) Error() string {
+	return e.Reason
+}
+
+// Verify checks that the tarball of a version matches its integrity
+// and its shasum, when they are known.
+func (v Version) Verify(tarball []byte) error {
+	if v.Integrity != "" {
+		if err := VerifyIntegrity(tarball, v.Integrity); err != nil {
+			return err
+		}
+	}
+	if v.Shasum != "" {
+		sum := sha1.Sum(tarball)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, v.Shasum) {
+			return VerifyError{fmt.Sprintf("shasum mismatch: expected %s, got %s", v.Shasum, actual)}
+		}
+	}
+	return nil
+}
+
+// Digest returns the digest the tarball of a version is verified against,
+// in the format of the integrity strings (ex. `sha512-<base64>`), or an
+// empty string if it isn't verified, ex. for git versions.
+func (v Version) Digest() string {
+	if v.Integrity != "" {
+		return v.Integrity
+	}
+	if sum, err := hex.DecodeString(v.Shasum); err == nil && len(sum) > 0 {
+		return "sha1-" + base64.StdEncoding.EncodeToString(sum)
+	}
+	return ""
+}
+
+// VerifyIntegrity checks that data matches an integrity string, made of
+// space-separated `<hash>-<base64 digest>` (ex. the `dist.integrity` of npm),
+// with the strongest hash it contains, like Subresource Integrity.
+func VerifyIntegrity(data []byte, integrity string) error {
+	for _, h := range integrityHashes {
+		var expected []string
+		for _, token := range strings.Fields(integrity) {
+			if strings.HasPrefix(token, h.name+"-") {
+				// options, ex. `?foo`, are ignored
+				digest := strings.SplitN(strings.TrimPrefix(token, h.name+"-"), "?", 2)[0]
+				expected = append(expected, digest)
+			}
+		}
+		if len(expected) == 0 {
+			continue
+		}
+
+		hasher := h.new()
+		hasher.Write(data)
+		actual := base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+		for _, digest := range expected {
+			if digest == actual {
+				return nil
+			}
+		}
+		return VerifyError{fmt.Sprintf("integrity mismatch: expected %s-%s, got %s-%s", h.name, expected[0], h.name, actual)}
+	}
+	return VerifyError{fmt.Sprintf("unsupported integrity `%s`", integrity)}
+}
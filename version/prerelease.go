@@ -0,0 +1,47 @@
+package version
+
+import (
+	"log"
+
+	"github.com/cdnjs/tools/packages"
+)
+
+// IsPrerelease determines if a version is a prerelease,
+// such as `1.0.0-rc.1`. Strings which do not look like
+// versions are not considered prereleases.
+func IsPrerelease(version string) bool {
+	l := parseLoose(version)
+	return l.ok && len(l.pre) > 0
+}
+
+// FilterPrereleases removes the versions excluded
+// by the prerelease policy of a package.
+func FilterPrereleases(config *packages.Autoupdate, versions []Version) []Version {
+	policy := config.PrereleasePolicy()
+	if policy == packages.PrereleaseAll {
+		return versions
+	}
+
+	// find the latest prerelease more recent than all stable versions
+	var latest *string
+	if policy == packages.PrereleaseLatest {
+		for i, v := range versions {
+			if latest == nil || Compare(v.Version, *latest) > 0 {
+				latest = &versions[i].Version
+			}
+		}
+		if latest != nil && !IsPrerelease(*latest) {
+			latest = nil
+		}
+	}
+
+	filtered := make([]Version, 0, len(versions))
+	for _, v := range versions {
+		if IsPrerelease(v.Version) && (latest == nil || v.Version != *latest) {
+			log.Printf("%s: prerelease %s is ignored\n", *config.Target, v.Version)
+			continue
+		}
+		filtered = append(filtered, v)
+	}
+	return filtered
+}
@@ -0,0 +1,272 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+var (
+	comparatorRegex = regexp.MustCompile(`^(<=|>=|<|>|=|\^|~)?v?([0-9]+|[xX*])(?:\.([0-9]+|[xX*]))?(?:\.([0-9]+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+	operatorRegex   = regexp.MustCompile(`(<=|>=|<|>|=|\^|~)\s+`)
+	hyphenRegex     = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+)
+
+// Range is a set of version constraints using the npm semver range
+// syntax, for example `>=2.0.0 <4`, `^5`, `~1.2` or `1.x || >=3.1.0`.
+//
+// As with npm, a prerelease version only satisfies a range if a comparator
+// in the same set is a prerelease of the same major, minor and patch, unless
+// prereleases are included with ContainsPrerelease.
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+type comparator struct {
+	op string // one of <, <=, >, >= or =
+	v  semver.Version
+}
+
+func (c comparator) satisfies(v semver.Version) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// ParseRange parses a semver range.
+func ParseRange(s string) (Range, error) {
+	r := Range{raw: s}
+	for _, set := range strings.Split(s, "||") {
+		comparators, err := parseComparatorSet(strings.TrimSpace(set))
+		if err != nil {
+			return r, errors.Wrapf(err, "invalid range `%s`", s)
+		}
+		r.sets = append(r.sets, comparators)
+	}
+	return r, nil
+}
+
+// String returns the range as written.
+func (r Range) String() string {
+	return r.raw
+}
+
+// Contains determines if a version satisfies the range. Versions are
+// parsed loosely, ex. `v3.1` is `3.1.0`, and the ones which still aren't
+// valid semver, ex. `not-a-version`, never do.
+func (r Range) Contains(version string) bool {
+	return r.contains(version, false)
+}
+
+// ContainsPrerelease determines if a version satisfies the range like
+// Contains, but prereleases satisfy it like the other versions, as with
+// the `includePrerelease` option of npm. The prereleases of an upper
+// bound are still excluded, ex. `3.0.0-rc.1` doesn't satisfy `^2.0.0`.
+func (r Range) ContainsPrerelease(version string) bool {
+	return r.contains(version, true)
+}
+
+func (r Range) contains(version string, includePrerelease bool) bool {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+
+	for _, set := range r.sets {
+		if setContains(set, v, includePrerelease) {
+			return true
+		}
+	}
+	return false
+}
+
+func setContains(set []comparator, v semver.Version, includePrerelease bool) bool {
+	for _, c := range set {
+		if !c.satisfies(v) {
+			return false
+		}
+	}
+
+	if len(v.Pre) == 0 {
+		return true
+	}
+
+	if includePrerelease {
+		for _, c := range set {
+			if c.op == "<" && len(c.v.Pre) == 0 && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+				return false
+			}
+		}
+		return true
+	}
+
+	// prereleases need to be explicitly allowed for their version tuple
+	for _, c := range set {
+		if len(c.v.Pre) > 0 && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// partial is a version of which the minor and patch may be missing or wildcards.
+type partial struct {
+	major, minor, patch int
+	// number of leading parts present, 0 for `*`
+	parts int
+	pre   string
+}
+
+func (p partial) version() semver.Version {
+	v := semver.Version{Major: uint64(p.major), Minor: uint64(p.minor), Patch: uint64(p.patch)}
+	if p.pre != "" {
+		for _, id := range strings.Split(p.pre, ".") {
+			pr, err := semver.NewPRVersion(id)
+			if err == nil {
+				v.Pre = append(v.Pre, pr)
+			}
+		}
+	}
+	return v
+}
+
+// Returns the smallest version above every version the partial matches.
+func (p partial) next() semver.Version {
+	switch p.parts {
+	case 1:
+		return semver.Version{Major: uint64(p.major + 1)}
+	case 2:
+		return semver.Version{Major: uint64(p.major), Minor: uint64(p.minor + 1)}
+	default:
+		return semver.Version{Major: uint64(p.major), Minor: uint64(p.minor), Patch: uint64(p.patch + 1)}
+	}
+}
+
+// Parses a comparator into its operator and partial version.
+func parsePartial(s string) (string, partial, error) {
+	m := comparatorRegex.FindStringSubmatch(s)
+	if m == nil {
+		return "", partial{}, fmt.Errorf("invalid comparator `%s`", s)
+	}
+
+	p := partial{pre: m[5]}
+	nums := []*int{&p.major, &p.minor, &p.patch}
+	for i, part := range m[2:5] {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return "", partial{}, err
+		}
+		*nums[i] = n
+		p.parts++
+	}
+	if p.parts < 3 {
+		p.pre = ""
+	}
+	return m[1], p, nil
+}
+
+func parseComparatorSet(s string) ([]comparator, error) {
+	if s == "" {
+		// any version
+		return []comparator{{op: ">=", v: semver.Version{}}}, nil
+	}
+
+	// 1.2.3 - 2.3.4
+	if m := hyphenRegex.FindStringSubmatch(s); m != nil {
+		_, from, err := parsePartial(m[1])
+		if err != nil {
+			return nil, err
+		}
+		_, to, err := parsePartial(m[2])
+		if err != nil {
+			return nil, err
+		}
+
+		set := []comparator{{op: ">=", v: from.version()}}
+		switch to.parts {
+		case 0:
+		case 3:
+			set = append(set, comparator{op: "<=", v: to.version()})
+		default:
+			set = append(set, comparator{op: "<", v: to.next()})
+		}
+		return set, nil
+	}
+
+	// allow whitespace between an operator and its version
+	s = operatorRegex.ReplaceAllString(s, "$1")
+
+	var set []comparator
+	for _, field := range strings.Fields(s) {
+		op, p, err := parsePartial(field)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, expandComparator(op, p)...)
+	}
+	return set, nil
+}
+
+// Turns a comparator on a partial version into comparators on versions.
+func expandComparator(op string, p partial) []comparator {
+	if p.parts == 0 {
+		if op == "<" || op == ">" {
+			// nothing is below 0.0.0 or above every version
+			return []comparator{{op: "<", v: semver.Version{}}}
+		}
+		return []comparator{{op: ">=", v: semver.Version{}}}
+	}
+
+	switch op {
+	case "^":
+		upper := semver.Version{Major: uint64(p.major + 1)}
+		if p.major == 0 && p.parts > 1 {
+			upper = semver.Version{Minor: uint64(p.minor + 1)}
+			if p.minor == 0 && p.parts > 2 {
+				upper = semver.Version{Patch: uint64(p.patch + 1)}
+			}
+		}
+		return []comparator{{op: ">=", v: p.version()}, {op: "<", v: upper}}
+	case "~":
+		upper := semver.Version{Major: uint64(p.major), Minor: uint64(p.minor + 1)}
+		if p.parts == 1 {
+			upper = semver.Version{Major: uint64(p.major + 1)}
+		}
+		return []comparator{{op: ">=", v: p.version()}, {op: "<", v: upper}}
+	case ">":
+		if p.parts < 3 {
+			return []comparator{{op: ">=", v: p.next()}}
+		}
+		return []comparator{{op: ">", v: p.version()}}
+	case "<=":
+		if p.parts < 3 {
+			return []comparator{{op: "<", v: p.next()}}
+		}
+		return []comparator{{op: "<=", v: p.version()}}
+	case "<", ">=":
+		return []comparator{{op: op, v: p.version()}}
+	default:
+		if p.parts < 3 {
+			return []comparator{{op: ">=", v: p.version()}, {op: "<", v: p.next()}}
+		}
+		return []comparator{{op: "=", v: p.version()}}
+	}
+}
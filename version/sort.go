@@ -3,18 +3,69 @@ package version
 import (
 	"context"
 	"log"
+
+	"github.com/cdnjs/tools/packages"
 )
 
 // ByTimeStamp implements the sort.Interface for []Version,
 // ordering from most recent to least recent time stamps.
+// Versions published at the same time are ordered from
+// greatest to lowest version.
 type ByDate []Version
 
 func (a ByDate) Len() int      { return len(a) }
 func (a ByDate) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a ByDate) Less(i, j int) bool {
+	if a[i].Date.Equal(a[j].Date) {
+		return Compare(a[i].Version, a[j].Version) > 0
+	}
 	return a[i].Date.After(a[j].Date)
 }
 
+// ByVersionAsset implements sort.Interface for []packages.Asset,
+// ordering from greatest to lowest Version using Compare.
+type ByVersionAsset []packages.Asset
+
+func (a ByVersionAsset) Len() int      { return len(a) }
+func (a ByVersionAsset) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByVersionAsset) Less(i, j int) bool {
+	return Compare(a[i].Version, a[j].Version) > 0
+}
+
+// ByVersionString implements sort.Interface for []string,
+// ordering from greatest to lowest version using Compare.
+type ByVersionString []string
+
+func (a ByVersionString) Len() int      { return len(a) }
+func (a ByVersionString) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByVersionString) Less(i, j int) bool {
+	return Compare(a[i], a[j]) > 0
+}
+
+// GetLatestStableVersion returns the greatest version that contains
+// no prerelease, using Compare.
+//
+// If no stable version is found (ex. all are prereleases or not
+// versions), we will pick the greatest one.
+//
+// If there are no versions at all, a nil *string will be returned.
+func GetLatestStableVersion(versions []string) *string {
+	var latestStable, greatest *string
+	for _, version := range versions {
+		version := version
+		if IsStable(version) && (latestStable == nil || Compare(version, *latestStable) > 0) {
+			latestStable = &version
+		}
+		if greatest == nil || Compare(version, *greatest) > 0 {
+			greatest = &version
+		}
+	}
+	if latestStable != nil {
+		return latestStable
+	}
+	return greatest
+}
+
 // GetMostRecentExistingVersion gets the most recent npm.Version based on time stamp
 // that is currently downloaded as well as all existing versions in npm.Version form.
 func GetMostRecentExistingVersion(ctx context.Context, existingVersions []string, npmVersions []Version) (*Version, []Version) {
@@ -31,7 +82,7 @@ func GetMostRecentExistingVersion(ctx context.Context, existingVersions []string
 	for _, existingVersion := range existingVersions {
 		if version, ok := npmMap[existingVersion]; ok {
 			allExisting = append(allExisting, version)
-			if mostRecent == nil || version.Date.After(mostRecent.Date) {
+			if mostRecent == nil || ByDate([]Version{version, *mostRecent}).Less(0, 1) {
 				mostRecent = &version // new most recent found
 			}
 			continue
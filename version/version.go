@@ -14,8 +14,16 @@ type Version struct {
 	Tarball string
 	Date    time.Time
 	Source  string // npm or git
+	// Integrity and Shasum are the digests of the tarball published
+	// on npm, ex. `sha512-<base64>` and a hex sha1, if known.
+	Integrity string
+	Shasum    string
 }
 
+// IsVersionIgnored determines if a version matches one of the ignored
+// patterns, or is outside of the version range of the package. Every
+// version is ignored if the range is invalid, which callers should
+// report beforehand with ParseRange.
 func IsVersionIgnored(config *packages.Autoupdate, version string) bool {
 	for _, ignored := range config.IgnoreVersions {
 		g := glob.MustCompile(ignored)
@@ -23,9 +31,23 @@ func IsVersionIgnored(config *packages.Autoupdate, version string) bool {
 			return true
 		}
 	}
+	if config.VersionRange != nil {
+		r, err := ParseRange(*config.VersionRange)
+		return err != nil || !InRange(config, r, version)
+	}
 	return false
 }
 
+// InRange determines if a version satisfies the version range of a package.
+// Prereleases satisfy it like the other versions unless the prerelease policy
+// only imports stable versions, since the policy selects the prereleases.
+func InRange(config *packages.Autoupdate, r Range, version string) bool {
+	if config.PrereleasePolicy() == packages.PrereleaseStable {
+		return r.Contains(version)
+	}
+	return r.ContainsPrerelease(version)
+}
+
 func VersionDiff(a []Version, b []string) []Version {
 	diff := make([]Version, 0)
 	m := make(map[string]bool)